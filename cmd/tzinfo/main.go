@@ -45,8 +45,8 @@ func printData(d tzif.Data) {
 	if d.Version == tzif.V1 || *printV1Flag {
 		printV1(d.V1Header, d.V1Data)
 	}
-	if d.Version > tzif.V1 {
-		printV2(d.V2Header, d.V2Data, d.V2Footer)
+	if d.Version.IsV2Plus() {
+		printV2(d)
 	}
 }
 
@@ -95,7 +95,8 @@ func printV1(h tzif.Header, b tzif.V1DataBlock) {
 	fmt.Println()
 }
 
-func printV2(h tzif.Header, b tzif.V2DataBlock, f tzif.Footer) {
+func printV2(d tzif.Data) {
+	h, b := d.V2Header, d.V2Data
 	printHeader(h)
 
 	fmt.Println("Data block", h.Version)
@@ -109,26 +110,27 @@ func printV2(h tzif.Header, b tzif.V2DataBlock, f tzif.Footer) {
 	fmt.Println()
 
 	if *printTransitionsFlag {
-		printTransitions(b)
+		printTransitions(d)
 	}
 
-	printFooter(f)
+	printFooter(d.V2Footer)
 }
 
-func printTransitions(b tzif.V2DataBlock) {
+func printTransitions(d tzif.Data) {
+	b := d.V2Data
 	fmt.Printf("Transitions (initial record: %s)\n", formatTimeRecord(b, 0))
-	for i, tt := range b.TransitionTimes {
-		fmt.Printf("  %s (%d)", formatTransitionTime(tt), tt)
-		fmt.Printf(" => ")
-		fmt.Printf("%s\n", formatTimeRecord(b, b.TransitionTypes[i]))
+	if n := len(b.TransitionTimes); n > 0 {
+		from := time.Unix(b.TransitionTimes[0], 0)
+		to := time.Unix(b.TransitionTimes[n-1]+1, 0)
+		for _, line := range strings.SplitAfter(d.Timeline(from, to), "\n") {
+			if line != "" {
+				fmt.Print("  ", line)
+			}
+		}
 	}
 	fmt.Println()
 }
 
-func formatTransitionTime(tt int64) string {
-	return time.Unix(tt, 0).UTC().Format(time.RFC1123)
-}
-
 func formatTimeRecord(b tzif.V2DataBlock, idx uint8) string {
 	r := b.LocalTimeTypeRecord[idx]
 	var dst string
@@ -139,13 +141,11 @@ func formatTimeRecord(b tzif.V2DataBlock, idx uint8) string {
 	return fmt.Sprintf("%s: %s (%d)%s", desig, time.Duration(r.Utoff)*time.Second, r.Utoff, dst)
 }
 
+// readDesign returns the designation starting at idx up to the first NUL.
 func readDesign(d []byte, idx uint8) string {
-	var desig string
-	for _, b := range d[idx:] {
-		if b == 0 {
-			break
-		}
-		desig += string(b)
+	if int(idx) >= len(d) {
+		return fmt.Sprintf("<invalid idx %d>", idx)
 	}
-	return desig
+	desig, _, _ := bytes.Cut(d[idx:], []byte{0})
+	return string(desig)
 }
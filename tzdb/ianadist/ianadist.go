@@ -12,10 +12,14 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync/atomic"
 )
 
 // TZDataFiles is a map of tzdb data file names to file contents.
@@ -42,12 +46,42 @@ type Release struct {
 	DataFiles TZDataFiles
 	// LeapSecondsFile is the content of the leap seconds file.
 	LeapSecondsFile []byte
+	// BackwardFile is the content of the "backward" file, which holds the
+	// links for the old names of renamed zones. It is not a data file,
+	// as it lacks the data file header.
+	BackwardFile []byte
 }
 
-// DefaultClient is the default client to download the IANA time zone database.
-// It is ready to use and is used by the top-level functions [Latest] and [Download]
+// defaultClient holds the client returned by DefaultClient.
+var defaultClient atomic.Pointer[Client]
+
+func init() {
+	defaultClient.Store(&Client{})
+}
+
+// DefaultClient returns the default client to download the IANA time zone
+// database. It is used by the top-level functions [Latest] and [Download]
 // in this package.
-var DefaultClient = &Client{}
+//
+// The returned client must not be modified, as it may be in use by other
+// goroutines. Use [SetDefaultClient] to replace it instead.
+func DefaultClient() *Client {
+	return defaultClient.Load()
+}
+
+// SetDefaultClient replaces the default client. If c is nil, a zero
+// Client is used. It is safe to call SetDefaultClient concurrently with
+// the top-level functions of this package; calls already in progress keep
+// using the previous client.
+//
+// Tests that fake the HTTP transport should prefer a local Client over
+// replacing the default one.
+func SetDefaultClient(c *Client) {
+	if c == nil {
+		c = &Client{}
+	}
+	defaultClient.Store(c)
+}
 
 // Client is a client to download the IANA time zone database.
 // The zero value is ready to use.
@@ -82,8 +116,19 @@ const (
 	dataFileMagicHeader = "# tzdb data for"
 	// leapSecondsFilename is the name of the leap seconds file in the archive.
 	leapSecondsFilename = "leapseconds"
+	// backwardFilename is the name of the file of backward-compatible
+	// links in the archive.
+	backwardFilename = "backward"
 	// versionFilename is the name of the version file in the archive.
 	versionFilename = "version"
+	// treeVersionFilename is the name of the file holding the version of
+	// the tzdb release in some compiled zoneinfo trees.
+	treeVersionFilename = "+VERSION"
+	// ziFilename is the name of the text form of the data that zic -b
+	// writes into compiled trees. Its first line names the version.
+	ziFilename = "tzdata.zi"
+	// ziVersionPrefix precedes the version in the first line of tzdata.zi.
+	ziVersionPrefix = "# version "
 	// emptyEtag is the empty etag value.
 	emptyEtag = ""
 )
@@ -119,6 +164,12 @@ func ReadArchive(r io.Reader) (*Release, error) {
 				return nil, fmt.Errorf("read leap seconds file: %w", err)
 			}
 			continue
+		case backwardFilename:
+			result.BackwardFile, err = io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("read backward file: %w", err)
+			}
+			continue
 		case versionFilename:
 			versionBytes, err := io.ReadAll(tr)
 			if err != nil {
@@ -179,7 +230,7 @@ func ReadArchive(r io.Reader) (*Release, error) {
 //
 // Latest is a wrapper around DefaultClient.Latest.
 func Latest(ctx context.Context, etag string) (*Release, string, error) {
-	return DefaultClient.Latest(ctx, etag)
+	return DefaultClient().Latest(ctx, etag)
 }
 
 // Latest downloads and unpacks the latest IANA time zone database.
@@ -235,7 +286,7 @@ func (c *Client) Latest(ctx context.Context, etag string) (*Release, string, err
 //
 // Download is a wrapper around DefaultClient.Download.
 func Download(ctx context.Context, path, etag string) (io.ReadCloser, string, error) {
-	return DefaultClient.Download(ctx, path, etag)
+	return DefaultClient().Download(ctx, path, etag)
 }
 
 // Download downloads the resource at the given path from the IANA time zone
@@ -311,3 +362,36 @@ func (c *Client) downloadIfNoneMatch(ctx context.Context, url, etag string) (io.
 	// Caller must take care of closing the response body.
 	return resp.Body, resp.Header.Get("etag"), nil
 }
+
+// TreeVersion returns the version of the tzdb release that a compiled
+// zoneinfo tree, such as os.DirFS("/usr/share/zoneinfo"), was built from.
+//
+// The version is read from the "+VERSION" file, if present, or from the
+// first line of "tzdata.zi", which reads "# version 2024b". An error is
+// returned if neither marker is found.
+func TreeVersion(fsys fs.FS) (string, error) {
+	b, err := fs.ReadFile(fsys, treeVersionFilename)
+	if err == nil {
+		if v := strings.TrimSpace(string(b)); v != "" {
+			return v, nil
+		}
+		return "", fmt.Errorf("empty %s file", treeVersionFilename)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("read %s: %w", treeVersionFilename, err)
+	}
+
+	b, err = fs.ReadFile(fsys, ziFilename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("no version marker: neither %s nor %s found", treeVersionFilename, ziFilename)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", ziFilename, err)
+	}
+	first, _, _ := strings.Cut(string(b), "\n")
+	v, ok := strings.CutPrefix(first, ziVersionPrefix)
+	if v = strings.TrimSpace(v); !ok || v == "" {
+		return "", fmt.Errorf("%s: no version in first line %q", ziFilename, first)
+	}
+	return v, nil
+}
@@ -6,7 +6,9 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -74,7 +76,9 @@ func TestLatest(t *testing.T) {
 		return resp, nil
 	})
 
-	DefaultClient = &Client{HTTPClient: httpClient}
+	prev := DefaultClient()
+	SetDefaultClient(&Client{HTTPClient: httpClient})
+	t.Cleanup(func() { SetDefaultClient(prev) })
 
 	ctx := context.Background()
 
@@ -101,6 +105,53 @@ func TestLatest(t *testing.T) {
 	}
 }
 
+func TestLatest_Concurrent(t *testing.T) {
+	const testEtag = "test-etag"
+	notModified := fakeClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusNotModified, Body: http.NoBody}, nil
+	})
+	prev := DefaultClient()
+	t.Cleanup(func() { SetDefaultClient(prev) })
+
+	// Run with -race to detect unsynchronized access to the default client.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetDefaultClient(&Client{HTTPClient: notModified})
+		}()
+		go func() {
+			defer wg.Done()
+			// The zero Client may still be the default, so only errors
+			// from the fake transport are of interest.
+			c := DefaultClient()
+			if c.HTTPClient == nil {
+				return
+			}
+			if _, etag, err := c.Latest(context.Background(), testEtag); err != nil || etag != testEtag {
+				t.Errorf("Latest(%q) = %q, %v, want %q, nil", testEtag, etag, err, testEtag)
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, etag, err := Latest(context.Background(), testEtag)
+	if err != nil || etag != testEtag {
+		t.Errorf("Latest(%q) = %q, %v, want %q, nil", testEtag, etag, err, testEtag)
+	}
+}
+
+func TestSetDefaultClient_Nil(t *testing.T) {
+	prev := DefaultClient()
+	t.Cleanup(func() { SetDefaultClient(prev) })
+
+	SetDefaultClient(nil)
+	if DefaultClient() == nil {
+		t.Error("DefaultClient() = nil after SetDefaultClient(nil), want zero Client")
+	}
+}
+
 func TestReadArchive(t *testing.T) {
 	data := mustReadTestData(t)
 	release, err := ReadArchive(bytes.NewReader(data))
@@ -108,4 +159,54 @@ func TestReadArchive(t *testing.T) {
 		t.Fatalf("ReadArchive(...): unexpected non-nil error: %v", err)
 	}
 	testTZDataFiles(t, release.DataFiles)
+	if !bytes.Contains(release.BackwardFile, []byte("Link\tAsia/Kolkata\t\tAsia/Calcutta")) {
+		t.Errorf("ReadArchive(...): BackwardFile does not contain the Asia/Calcutta link")
+	}
+}
+
+func TestTreeVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "+VERSION",
+			files: map[string]string{"+VERSION": "2024b\n", "tzdata.zi": "# version 2024a\n"},
+			want:  "2024b",
+		},
+		{
+			name:  "tzdata.zi",
+			files: map[string]string{"tzdata.zi": "# version 2024b\n# This zic input file is in the public domain.\n"},
+			want:  "2024b",
+		},
+		{
+			name:    "no marker",
+			files:   map[string]string{"UTC": "TZif2"},
+			wantErr: true,
+		},
+		{
+			name:    "tzdata.zi without version",
+			files:   map[string]string{"tzdata.zi": "R d 1916 o - Jun 14 23s 1 S\n"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := TreeVersion(os.DirFS(dir))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TreeVersion() error = %v, want error: %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TreeVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
@@ -0,0 +1,128 @@
+package tzfile
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FileDiff is the line-level difference between two files.
+type FileDiff struct {
+	Rules LineDiff[RuleLine]
+	Zones LineDiff[ZoneLine]
+	Links LineDiff[LinkLine]
+}
+
+// Empty returns true if the files do not differ.
+func (d FileDiff) Empty() bool {
+	return d.Rules.Empty() && d.Zones.Empty() && d.Links.Empty()
+}
+
+// LineDiff is the difference between the lines of one type of two files.
+type LineDiff[T Line] struct {
+	Added   []T             // lines only in the second file
+	Removed []T             // lines only in the first file
+	Changed []LineChange[T] // lines with the same key but different fields
+}
+
+// Empty returns true if no lines were added, removed or changed.
+func (d LineDiff[T]) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// LineChange is a line that was changed between two files.
+type LineChange[T Line] struct {
+	Old T
+	New T
+}
+
+// DiffFiles returns the rule, zone and link lines that were added, removed
+// or changed from a to b.
+//
+// Lines are matched by a stable key: rule lines by NAME, FROM, TO and IN,
+// zone lines by the NAME of the zone, STDOFF and UNTIL, and link lines by
+// LINK-NAME. Matched lines whose other fields differ are reported as
+// changed. Line numbers and the text of the lines are ignored.
+func DiffFiles(a, b File) FileDiff {
+	return FileDiff{
+		Rules: diffLines(a.Rules, b.Rules, ruleKeys),
+		Zones: diffLines(a.Zones, b.Zones, zoneKeys),
+		Links: diffLines(a.Links, b.Links, linkKeys),
+	}
+}
+
+// diffLines matches the lines of a and b by the keys returned by keys.
+// If several lines share a key, they are matched in order.
+func diffLines[T Line](a, b []T, keys func([]T) []string) LineDiff[T] {
+	var (
+		d         LineDiff[T]
+		unmatched = make(map[string][]int) // indices into a
+		removed   = make([]bool, len(a))
+	)
+	for i, k := range keys(a) {
+		unmatched[k] = append(unmatched[k], i)
+		removed[i] = true
+	}
+	for i, k := range keys(b) {
+		if len(unmatched[k]) == 0 {
+			d.Added = append(d.Added, b[i])
+			continue
+		}
+		j := unmatched[k][0]
+		unmatched[k] = unmatched[k][1:]
+		removed[j] = false
+		if !equalFields(a[j], b[i]) {
+			d.Changed = append(d.Changed, LineChange[T]{Old: a[j], New: b[i]})
+		}
+	}
+	for i, r := range removed {
+		if r {
+			d.Removed = append(d.Removed, a[i])
+		}
+	}
+	return d
+}
+
+// equalFields returns true if the lines have equal fields, ignoring where
+// they come from.
+func equalFields[T Line](a, b T) bool {
+	va, vb := reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem()
+	for i := 0; i < va.NumField(); i++ {
+		if va.Type().Field(i).Type == reflect.TypeOf(lineInFile{}) {
+			continue
+		}
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+func ruleKeys(rules []RuleLine) []string {
+	keys := make([]string, len(rules))
+	for i, r := range rules {
+		keys[i] = fmt.Sprintf("%s %v %v %v", r.Name, r.From, r.To, r.In)
+	}
+	return keys
+}
+
+// zoneKeys returns the keys of zone lines. Continuation lines are keyed by
+// the name of the zone they continue.
+func zoneKeys(zones []ZoneLine) []string {
+	keys := make([]string, len(zones))
+	var name string
+	for i, z := range zones {
+		if !z.Continuation {
+			name = z.Name
+		}
+		keys[i] = fmt.Sprintf("%s %v %+v", name, z.Offset, z.Until)
+	}
+	return keys
+}
+
+func linkKeys(links []LinkLine) []string {
+	keys := make([]string, len(links))
+	for i, l := range links {
+		keys[i] = l.To
+	}
+	return keys
+}
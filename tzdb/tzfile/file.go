@@ -0,0 +1,225 @@
+package tzfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// File holds all lines of a parsed tzdata or leapseconds file.
+type File struct {
+	Rules []RuleLine
+	Zones []ZoneLine // zone lines, each followed by its continuation lines
+	Links []LinkLine
+	Leaps []LeapLine
+
+	// Expires is the expires line of the file, or nil if there is none.
+	Expires *ExpiresLine
+
+	// Warnings holds problems that were recovered from while parsing.
+	// It is only populated if recovery or skipping was enabled in the
+	// ParseOptions.
+	Warnings []error
+}
+
+// ParseOptions controls the behavior of ParseWithOptions.
+// The zero value is the strict behavior of Parse.
+type ParseOptions struct {
+	// RecoverMultipleExpires makes the parser accept files with more than
+	// one expires line. The expires line with the latest date wins and a
+	// warning is recorded in File.Warnings for every other one.
+	// By default, multiple expires lines are an error.
+	RecoverMultipleExpires bool
+
+	// SkipUnknownLines makes the parser skip lines that do not start with
+	// a known keyword, such as directives of newer zic versions, and record
+	// a warning in File.Warnings for each of them.
+	// By default, unknown lines are an error.
+	SkipUnknownLines bool
+}
+
+// Parse reads and parses all lines of a tzdata or leapseconds file.
+//
+// In addition to the syntax checks of the Scanner, Parse checks that the
+// file contains at most one expires line, as required by zic, and that no
+// link shadows a zone (see CheckLinks).
+func Parse(r io.Reader) (*File, error) {
+	return ParseWithOptions(r, ParseOptions{})
+}
+
+// ParseWithOptions is like Parse but with the given options.
+func ParseWithOptions(r io.Reader, opts ParseOptions) (*File, error) {
+	f := &File{}
+	if err := f.parse(NewScanner(r), opts); err != nil {
+		return nil, err
+	}
+	if err := f.CheckLinks(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// ParseFiles parses the given files, such as the DataFiles of an
+// ianadist.Release, into a single File. The map keys are file names; they
+// are recorded in the lines, see FileName. Files are parsed in the order
+// of their names, and errors are prefixed with the name of the file.
+//
+// The checks of Parse apply to the combined lines of all files.
+func ParseFiles(files map[string][]byte, opts ParseOptions) (*File, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	f := &File{}
+	for _, name := range names {
+		s := NewScanner(bytes.NewReader(files[name]))
+		s.fileName = name
+		if err := f.parse(s, opts); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if err := f.CheckLinks(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parse adds the lines read by s to f.
+func (f *File) parse(s *Scanner, opts ParseOptions) error {
+	if opts.SkipUnknownLines {
+		s.unknownLine = func(source lineInFile) {
+			f.Warnings = append(f.Warnings, newParseError(source, errors.New("skipping unknown line type")))
+		}
+	}
+	for s.Scan() {
+		switch l := s.Line().(type) {
+		case RuleLine:
+			f.Rules = append(f.Rules, l)
+		case ZoneLine:
+			f.Zones = append(f.Zones, l)
+		case LinkLine:
+			f.Links = append(f.Links, l)
+		case LeapLine:
+			f.Leaps = append(f.Leaps, l)
+		case ExpiresLine:
+			if err := f.addExpires(l, opts); err != nil {
+				return err
+			}
+		}
+	}
+	return s.Err()
+}
+
+// backwardFile is the name of the tzdb file that holds the links for
+// the old names of renamed zones.
+const backwardFile = "backward"
+
+// DeprecatedNames returns the LINK-NAMEs of the link lines read from the
+// "backward" file by ParseFiles, sorted. These are old names of zones kept
+// for compatibility, which user interfaces should not offer.
+func (f *File) DeprecatedNames() []string {
+	var names []string
+	for _, l := range f.Links {
+		if l.fileName == backwardFile {
+			names = append(names, l.To)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckLinks returns an error for every link line whose LINK-NAME equals
+// the NAME of a zone, joined with errors.Join. Such a link would shadow the
+// zone; it usually results from swapping TARGET and LINK-NAME.
+// Callers that combine the lines of several files should call CheckLinks
+// on the combined File.
+func (f *File) CheckLinks() error {
+	zones := make(map[string]int) // line numbers by zone name
+	for _, z := range f.Zones {
+		if !z.Continuation {
+			zones[z.Name] = z.lineNum
+		}
+	}
+	var errs error
+	for _, l := range f.Links {
+		if n, ok := zones[l.To]; ok {
+			errs = errors.Join(errs, newParseError(l.lineInFile, fmt.Errorf("link name %q collides with zone on line %d", l.To, n)))
+		}
+	}
+	return errs
+}
+
+// addExpires records an expires line, handling duplicates according to opts.
+func (f *File) addExpires(l ExpiresLine, opts ParseOptions) error {
+	if f.Expires == nil {
+		f.Expires = &l
+		return nil
+	}
+	prev := *f.Expires
+	if !opts.RecoverMultipleExpires {
+		return newParseError(l.lineInFile, fmt.Errorf("multiple expires lines, previous on line %d", prev.lineNum))
+	}
+	// The latest expiration wins; the other line is reported as a warning.
+	ignored := l
+	if l.instant().After(prev.instant()) {
+		f.Expires = &l
+		ignored = prev
+	}
+	f.Warnings = append(f.Warnings, newParseError(ignored.lineInFile, errors.New("ignoring superseded expires line")))
+	return nil
+}
+
+// RemoveDuplicateRules removes rule lines whose fields equal those of an
+// earlier rule line, which would otherwise double the transitions of the
+// rule set, and returns the removed lines.
+func (f *File) RemoveDuplicateRules() []RuleLine {
+	var kept, removed []RuleLine
+	for _, r := range f.Rules {
+		duplicate := false
+		for _, k := range kept {
+			if equalFields(k, r) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			removed = append(removed, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	f.Rules = kept
+	return removed
+}
+
+// ZonesUsingRule returns the names of the zones with a zone or
+// continuation line that uses the rule set of the given name, in the order
+// of the zones in the file. Each zone is returned once.
+func (f *File) ZonesUsingRule(name string) []string {
+	var (
+		names []string
+		zone  string
+	)
+	for _, z := range f.Zones {
+		if !z.Continuation {
+			zone = z.Name
+		}
+		if z.Rules.Form != ZoneRulesName || z.Rules.Name != name {
+			continue
+		}
+		if len(names) == 0 || names[len(names)-1] != zone {
+			names = append(names, zone)
+		}
+	}
+	return names
+}
+
+// instant returns the expiration time of the line in UTC.
+func (l ExpiresLine) instant() time.Time {
+	return time.Date(l.Year, l.Month, l.Day, l.Time.Hours, l.Time.Minutes, l.Time.Seconds, 0, time.UTC)
+}
@@ -0,0 +1,199 @@
+package tzfile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const multipleExpires = `
+Leap  2016  Dec    31   23:59:60  +     S
+Expires  2025  Jun    28   00:00:00
+Expires  2025  Dec    28   00:00:00
+Expires  2024  Dec    28   00:00:00
+`
+
+func TestParse_MultipleExpires(t *testing.T) {
+	_, err := Parse(strings.NewReader(multipleExpires))
+	if err == nil {
+		t.Fatal("Parse() = nil error, want error")
+	}
+	if !strings.Contains(err.Error(), "multiple expires lines, previous on line 3") {
+		t.Errorf("Parse() = %v, want multiple expires error", err)
+	}
+}
+
+func TestParseWithOptions_RecoverMultipleExpires(t *testing.T) {
+	f, err := ParseWithOptions(strings.NewReader(multipleExpires), ParseOptions{RecoverMultipleExpires: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ExpiresLine{Year: 2025, Month: time.December, Day: 28}
+	if diff := cmp.Diff(want, f.Expires, cmpopts.IgnoreTypes(lineInFile{})); diff != "" {
+		t.Errorf("Expires mismatch (-want +got):\n%s", diff)
+	}
+	if f.Expires.LineNum() != 4 {
+		t.Errorf("Expires.LineNum() = %d, want 4", f.Expires.LineNum())
+	}
+	if len(f.Warnings) != 2 {
+		t.Fatalf("got %d warnings, want 2: %v", len(f.Warnings), f.Warnings)
+	}
+	for i, line := range []int{3, 5} {
+		var perr ParseError
+		if !errors.As(f.Warnings[i], &perr) || perr.Source.LineNum() != line {
+			t.Errorf("Warnings[%d] = %v, want warning for line %d", i, f.Warnings[i], line)
+		}
+	}
+	if len(f.Leaps) != 1 {
+		t.Errorf("got %d leap lines, want 1", len(f.Leaps))
+	}
+}
+
+func TestParseWithOptions_SkipUnknownLines(t *testing.T) {
+	input := `
+Rule	Swiss	1941	1942	-	May	Mon>=1	1:00	1:00	S
+Stray	directive
+Link	Europe/Zurich	Europe/Vaduz
+`
+	if _, err := Parse(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), "unknown line type") {
+		t.Errorf("Parse() = %v, want unknown line type error", err)
+	}
+
+	f, err := ParseWithOptions(strings.NewReader(input), ParseOptions{SkipUnknownLines: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Rules) != 1 || len(f.Links) != 1 {
+		t.Errorf("got %d rules and %d links, want 1 and 1", len(f.Rules), len(f.Links))
+	}
+	var perr ParseError
+	if len(f.Warnings) != 1 || !errors.As(f.Warnings[0], &perr) || perr.Source.LineNum() != 3 {
+		t.Errorf("Warnings = %v, want one warning for line 3", f.Warnings)
+	}
+}
+
+func TestDiffFiles(t *testing.T) {
+	const base = `
+Rule	EU	1981	max	-	Mar	lastSun	1:00u	1:00	S
+Rule	EU	1996	max	-	Oct	lastSun	1:00u	0	-
+Zone	Europe/Zurich	0:34:08	-	LMT	1853 Jul 16
+			1:00	EU	CE%sT
+Link	Europe/Zurich	Europe/Vaduz
+`
+	a, err := Parse(strings.NewReader(base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Add a rule; the lines of a keep their values but not their line numbers.
+	b, err := Parse(strings.NewReader("Rule\tEU\t1977\t1980\t-\tApr\tSun>=1\t1:00u\t1:00\tS\n" + base))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := DiffFiles(*a, *b)
+	if len(d.Rules.Added) != 1 || d.Rules.Added[0].From != 1977 {
+		t.Errorf("Rules.Added = %+v, want the 1977 rule", d.Rules.Added)
+	}
+	if len(d.Rules.Removed) != 0 || len(d.Rules.Changed) != 0 {
+		t.Errorf("Rules.Removed = %+v, Rules.Changed = %+v, want none", d.Rules.Removed, d.Rules.Changed)
+	}
+	if !d.Zones.Empty() || !d.Links.Empty() {
+		t.Errorf("Zones = %+v, Links = %+v, want no differences", d.Zones, d.Links)
+	}
+
+	// Change the SAVE of a rule and remove the link.
+	b.Rules[2].Save = Time{Duration: 2 * time.Hour, Form: DaylightSavingTime}
+	b.Links = nil
+	d = DiffFiles(*a, *b)
+	if len(d.Rules.Changed) != 1 || d.Rules.Changed[0].Old.Save.Duration != 0 || d.Rules.Changed[0].New.Save.Duration != 2*time.Hour {
+		t.Errorf("Rules.Changed = %+v, want SAVE change of the October rule", d.Rules.Changed)
+	}
+	if len(d.Links.Removed) != 1 || d.Links.Removed[0].To != "Europe/Vaduz" {
+		t.Errorf("Links.Removed = %+v, want Europe/Vaduz", d.Links.Removed)
+	}
+	if !DiffFiles(*a, *a).Empty() {
+		t.Errorf("DiffFiles(a, a).Empty() = false, want true")
+	}
+}
+
+func TestFile_RemoveDuplicateRules(t *testing.T) {
+	f, err := Parse(strings.NewReader(`
+Rule	EU	1981	max	-	Mar	lastSun	1:00u	1:00	S
+Rule	EU	1996	max	-	Oct	lastSun	1:00u	0	-
+Rule	EU	1981	max	-	Mar	lastSun	1:00u	1:00	S
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed := f.RemoveDuplicateRules()
+	if len(removed) != 1 || removed[0].LineNum() != 4 {
+		t.Errorf("RemoveDuplicateRules() = %+v, want the rule on line 4", removed)
+	}
+	if len(f.Rules) != 2 || f.Rules[0].In != time.March || f.Rules[1].In != time.October {
+		t.Errorf("Rules = %+v, want the March and October rules", f.Rules)
+	}
+}
+
+func TestFile_ZonesUsingRule(t *testing.T) {
+	f, err := Parse(strings.NewReader(extendedExample + `
+Zone	Europe/Brussels	0:17:30	-	LMT	1880
+			1:00	EU	CE%sT
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string][]string{
+		"Swiss":   {"Europe/Zurich"},
+		"EU":      {"Europe/Zurich", "Europe/Brussels"},
+		"Belgium": nil,
+	}
+	for name, want := range tests {
+		if diff := cmp.Diff(want, f.ZonesUsingRule(name)); diff != "" {
+			t.Errorf("ZonesUsingRule(%q) mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestParse_LinkShadowsZone(t *testing.T) {
+	// The fields of the link line are swapped by mistake.
+	input := extendedExample + `
+Link	Europe/Vaduz	Europe/Zurich
+`
+	_, err := Parse(strings.NewReader(input))
+	if err == nil || !strings.Contains(err.Error(), `link name "Europe/Zurich" collides with zone on line 12`) {
+		t.Errorf("Parse() = %v, want link collision error", err)
+	}
+	var perr ParseError
+	if !errors.As(err, &perr) || perr.Source.LineNum() != 18 {
+		t.Errorf("Parse() = %v, want ParseError on line 18", err)
+	}
+}
+
+func TestParseFiles_DeprecatedNames(t *testing.T) {
+	files := map[string][]byte{
+		"europe": []byte(extendedExample),
+		"backward": []byte(`
+Link	Europe/Zurich	Europe/Old_Zurich
+Link	Europe/Zurich	Switzerland	# backward-compatible alias
+`),
+	}
+	f, err := ParseFiles(files, ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"Europe/Old_Zurich", "Switzerland"}, f.DeprecatedNames()); diff != "" {
+		t.Errorf("DeprecatedNames() mismatch (-want +got):\n%s", diff)
+	}
+	if len(f.Links) != 3 || f.Links[2].FileName() != "europe" {
+		t.Errorf("Links = %+v, want the europe link last", f.Links)
+	}
+
+	files["europe"] = []byte("Zone\tEurope/Zurich\t1:00\tnope\n")
+	if _, err := ParseFiles(files, ParseOptions{}); err == nil || !strings.HasPrefix(err.Error(), "europe: ") {
+		t.Errorf("ParseFiles() = %v, want error prefixed with the file name", err)
+	}
+}
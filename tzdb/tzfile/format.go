@@ -0,0 +1,212 @@
+package tzfile
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteTo writes the rule line to w in the format it is parsed from,
+// with fields separated by tabs and terminated by a newline.
+//
+// Fields that still hold the value parsed from the text of the line are
+// written as they appear in the text, so that unchanged lines round-trip
+// except for white space and comments. Other fields are written in a
+// normalized form.
+func (r RuleLine) WriteTo(w io.Writer) (int64, error) {
+	src, _ := splitLine(r.lineText)
+	parseTO := func(s string) (Year, error) { return parseRuleTO(s, r.From) }
+	formatTO := func(y Year) string {
+		if y == r.From {
+			return "only"
+		}
+		return formatYear(y)
+	}
+	return writeFields(w, "",
+		"Rule",
+		keepField(src, 1, parseRuleNAME, r.Name, quoteField),
+		keepField(src, 2, parseRuleFROM, r.From, formatYear),
+		keepField(src, 3, parseTO, r.To, formatTO),
+		"-",
+		keepField(src, 5, parseRuleIN, r.In, formatMonth),
+		keepField(src, 6, parseRuleON, r.On, formatDay),
+		keepField(src, 7, parseRuleAT, r.At, formatAT),
+		keepField(src, 8, parseRuleSAVE, r.Save, formatSAVE),
+		keepField(src, 9, parseRuleLETTERS, r.Letter, formatLetters),
+	)
+}
+
+// WriteTo writes the zone line to w in the format it is parsed from,
+// with fields separated by tabs and terminated by a newline.
+// Continuation lines are indented by three tabs.
+//
+// Like for RuleLine.WriteTo, unchanged fields are written as they appear
+// in the text of the line.
+func (z ZoneLine) WriteTo(w io.Writer) (int64, error) {
+	src, _ := splitLine(z.lineText)
+	var (
+		prefix string
+		fields []string
+		i      int // index of the STDOFF field in the text
+	)
+	if z.Continuation {
+		prefix = "\t\t\t"
+	} else {
+		fields = append(fields, "Zone", keepField(src, 1, parseZoneNAME, z.Name, quoteField))
+		i = 2
+	}
+	fields = append(fields,
+		keepField(src, i, parseZoneSTDOFF, z.Offset, formatDuration),
+		keepField(src, i+1, parseZoneRULES, z.Rules, formatZoneRules),
+		keepField(src, i+2, parseZoneFORMAT, z.Format, quoteField),
+	)
+	if z.Until.Defined {
+		until := formatUntil(z.Until)
+		if i+3 < len(src) {
+			orig := strings.Join(src[i+3:], " ")
+			if u, err := parseZoneUNTIL(orig); err == nil && u == z.Until {
+				until = orig
+			}
+		}
+		fields = append(fields, until)
+	}
+	return writeFields(w, prefix, fields...)
+}
+
+// writeFields writes the prefix and the tab-separated fields as a line to w.
+func writeFields(w io.Writer, prefix string, fields ...string) (int64, error) {
+	n, err := io.WriteString(w, prefix+strings.Join(fields, "\t")+"\n")
+	return int64(n), err
+}
+
+// keepField returns the i-th of the fields src of the text of the line,
+// quoted if needed, if it parses to v. Otherwise, it returns v formatted
+// by format.
+func keepField[T comparable](src []string, i int, parse func(string) (T, error), v T, format func(T) string) string {
+	if i < len(src) {
+		if orig, err := parse(src[i]); err == nil && orig == v {
+			return quoteField(src[i])
+		}
+	}
+	return format(v)
+}
+
+// quoteField quotes s if it is empty or contains white space or a
+// sharp character.
+func quoteField(s string) string {
+	if s == "" || strings.ContainsAny(s, " \f\r\n\t\v#") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+func formatYear(y Year) string {
+	switch y {
+	case MinYear:
+		return "min"
+	case MaxYear:
+		return "max"
+	}
+	return strconv.Itoa(int(y))
+}
+
+func formatMonth(m time.Month) string {
+	return m.String()[:3]
+}
+
+func formatDay(d Day) string {
+	weekday := d.Day.String()[:3]
+	switch d.Form {
+	case DayFormLast:
+		return "last" + weekday
+	case DayFormAfter:
+		return fmt.Sprintf("%s>=%d", weekday, d.Num)
+	case DayFormBefore:
+		return fmt.Sprintf("%s<=%d", weekday, d.Num)
+	default:
+		return strconv.Itoa(d.Num)
+	}
+}
+
+// formatDuration formats d as [-]h:mm[:ss[.fff]], or 0 if d is zero.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+	var sign string
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	h, m := d/time.Hour, d/time.Minute%60
+	sec, ms := d/time.Second%60, d/time.Millisecond%1000
+	switch {
+	case ms != 0:
+		return fmt.Sprintf("%s%d:%02d:%02d.%03d", sign, h, m, sec, ms)
+	case sec != 0:
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, h, m, sec)
+	default:
+		return fmt.Sprintf("%s%d:%02d", sign, h, m)
+	}
+}
+
+// formatAT formats a time in the AT column format.
+// Wall clock time is the default and has no suffix.
+func formatAT(t Time) string {
+	switch t.Form {
+	case StandardTime:
+		return formatDuration(t.Duration) + "s"
+	case UniversalTime:
+		return formatDuration(t.Duration) + "u"
+	default:
+		return formatDuration(t.Duration)
+	}
+}
+
+// formatSAVE formats a time in the SAVE column format.
+// The suffix is omitted if the form is the default for the duration.
+func formatSAVE(t Time) string {
+	s := formatDuration(t.Duration)
+	switch {
+	case t.Form == StandardTime && t.Duration != 0:
+		return s + "s"
+	case t.Form == DaylightSavingTime && t.Duration == 0:
+		return s + "d"
+	default:
+		return s
+	}
+}
+
+func formatLetters(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return quoteField(s)
+}
+
+func formatZoneRules(r ZoneRules) string {
+	switch r.Form {
+	case ZoneRulesName:
+		return quoteField(r.Name)
+	case ZoneRulesTime:
+		return formatSAVE(r.Time)
+	default:
+		return "-"
+	}
+}
+
+func formatUntil(u Until) string {
+	fields := []string{strconv.Itoa(u.Year)}
+	if u.Parts.Has(untilMonthOnly) {
+		fields = append(fields, formatMonth(u.Month))
+	}
+	if u.Parts.Has(untilDayOnly) {
+		fields = append(fields, formatDay(u.Day))
+	}
+	if u.Parts.Has(untilTimeOnly) {
+		fields = append(fields, formatAT(u.Time))
+	}
+	return strings.Join(fields, " ")
+}
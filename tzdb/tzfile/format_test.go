@@ -0,0 +1,101 @@
+package tzfile
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteTo_RoundTrip(t *testing.T) {
+	input := `Rule	EU	1981	max	-	Mar	lastSun	1u	1:00	S
+Rule	Swiss	1941	1942	-	May	Mon>=1	01:00	1:00	S
+Zone	Europe/Zurich	0:34:08	-	LMT	1853 Jul 16
+			0:29:45.50	-	BMT	1894 Jun
+			1:00	EU	CE%sT
+`
+	var sb strings.Builder
+	s := NewScanner(strings.NewReader(input))
+	for s.Scan() {
+		if _, err := s.Line().(io.WriterTo).WriteTo(&sb); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(input, sb.String()); diff != "" {
+		t.Errorf("WriteTo() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRuleLine_WriteTo_Changed(t *testing.T) {
+	s := NewScanner(strings.NewReader("Rule EU 1981 max - Mar lastSun 1u 1:00 S\n"))
+	if !s.Scan() {
+		t.Fatal(s.Err())
+	}
+	r := s.Line().(RuleLine)
+
+	// Unchanged fields keep their original text.
+	var sb strings.Builder
+	if _, err := r.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sb.String(), "Rule\tEU\t1981\tmax\t-\tMar\tlastSun\t1u\t1:00\tS\n"; got != want {
+		t.Errorf("WriteTo() = %q, want %q", got, want)
+	}
+
+	// Changed fields are normalized.
+	r.At = Time{Duration: 2 * time.Hour, Form: UniversalTime}
+	r.To = r.From
+	r.Letter = ""
+	sb.Reset()
+	if _, err := r.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sb.String(), "Rule\tEU\t1981\tonly\t-\tMar\tlastSun\t2:00u\t1:00\t-\n"; got != want {
+		t.Errorf("WriteTo() = %q, want %q", got, want)
+	}
+}
+
+func TestZoneLine_WriteTo_Normalized(t *testing.T) {
+	tests := []struct {
+		z    ZoneLine
+		want string
+	}{
+		{
+			z: ZoneLine{Name: "Europe/Zurich", Offset: 34*time.Minute + 8*time.Second, Rules: ZoneRules{Form: ZoneRulesStandard}, Format: "LMT",
+				Until: Until{Defined: true, Year: 1853, Month: time.July, Day: Day{Form: DayFormDayNum, Num: 16}, Parts: UntilDay}},
+			want: "Zone\tEurope/Zurich\t0:34:08\t-\tLMT\t1853 Jul 16\n",
+		},
+		{
+			z:    ZoneLine{Continuation: true, Offset: -(2*time.Hour + 30*time.Minute), Rules: ZoneRules{Form: ZoneRulesTime, Time: Time{Duration: time.Hour, Form: DaylightSavingTime}}, Format: "-0130"},
+			want: "\t\t\t-2:30\t1:00\t-0130\n",
+		},
+	}
+	for _, tt := range tests {
+		var sb strings.Builder
+		if _, err := tt.z.WriteTo(&sb); err != nil {
+			t.Fatal(err)
+		}
+		if got := sb.String(); got != tt.want {
+			t.Errorf("WriteTo() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestZoneLine_WriteTo_Quoted(t *testing.T) {
+	s := NewScanner(strings.NewReader("Zone\tEtc/\"Quoted Zone\"\t0\t-\t\"Local time\"\n"))
+	if !s.Scan() {
+		t.Fatal(s.Err())
+	}
+	var sb strings.Builder
+	if _, err := s.Line().(ZoneLine).WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sb.String(), "Zone\t\"Etc/Quoted Zone\"\t0\t-\t\"Local time\"\n"; got != want {
+		t.Errorf("WriteTo() = %q, want %q", got, want)
+	}
+}
@@ -38,10 +38,17 @@ func (ExpiresLine) isLine() {}
 
 // lineInFile helps lines implement the Line interface.
 type lineInFile struct {
+	fileName string // empty if not read by ParseFiles
 	lineNum  int
 	lineText string
 }
 
+// FileName returns the name of the file the line was read from, as passed
+// to ParseFiles, or the empty string if the name is not known.
+func (l lineInFile) FileName() string {
+	return l.fileName
+}
+
 // LineNum returns the line number of the line in the file.
 func (l lineInFile) LineNum() int {
 	return l.lineNum
@@ -76,13 +83,29 @@ func (e ParseError) Error() string {
 // Scanner is a scanner for tzdata and leapsecond files.
 // It reads lines from an io.Reader and parses them into Line values.
 type Scanner struct {
-	scanner *bufio.Scanner
+	scanner  *bufio.Scanner
+	fileName string // recorded in the lines, see lineInFile
 
 	lineNumber               int
 	zoneContinuationExpected bool
 
-	line Line
-	err  error
+	// unknownLine, if set, is called for lines of unknown type,
+	// which are then skipped instead of causing an error.
+	unknownLine func(source lineInFile)
+
+	line   Line
+	err    error
+	counts LineCounts
+}
+
+// LineCounts holds the number of lines of each type that were read.
+type LineCounts struct {
+	Rules         int
+	Zones         int // zone lines, excluding continuation lines
+	Continuations int // zone continuation lines
+	Links         int
+	Leaps         int
+	Expires       int
 }
 
 // NewScanner creates a new Scanner that reads from r.
@@ -100,7 +123,7 @@ func (s *Scanner) Scan() bool {
 	for s.scanner.Scan() {
 		s.lineNumber++
 		line := s.scanner.Text()
-		source := lineInFile{lineNum: s.lineNumber, lineText: line}
+		source := lineInFile{fileName: s.fileName, lineNum: s.lineNumber, lineText: line}
 		fields, err := splitLine(line)
 		if err != nil {
 			s.err = err
@@ -109,26 +132,35 @@ func (s *Scanner) Scan() bool {
 		if fields == nil {
 			continue // skip comment or empty line
 		}
+		if err := s.checkContinuation(line, fields); err != nil {
+			s.line = nil
+			s.err = newParseError(source, err)
+			return false
+		}
 		switch {
-		case strings.HasPrefix(line, "Zone") || s.zoneContinuationExpected:
+		case strings.HasPrefix(fields[0], "Zone") || s.zoneContinuationExpected:
 			var zone ZoneLine
 			if s.zoneContinuationExpected {
-				zone, s.err = parseZoneContinuationLine(fields)
+				zone, s.err = parseZoneContinuationLine(source, fields)
 			} else {
 				zone, s.err = parseZoneLine(source, fields)
 			}
 			s.line = zone
 			// If the UNTIL column is defined, we expect a continuation line to follow.
 			s.zoneContinuationExpected = zone.Until.Defined
-		case strings.HasPrefix(line, "Rule"):
+		case strings.HasPrefix(fields[0], "Rule"):
 			s.line, s.err = parseRuleLine(source, fields)
-		case strings.HasPrefix(line, "Link"):
+		case strings.HasPrefix(fields[0], "Link"):
 			s.line, s.err = parseLinkLine(source, fields)
-		case strings.HasPrefix(line, "Leap"):
+		case strings.HasPrefix(fields[0], "Leap"):
 			s.line, s.err = parseLeapLine(source, fields)
-		case strings.HasPrefix(line, "Expires"):
+		case strings.HasPrefix(fields[0], "Expires"):
 			s.line, s.err = parseExpiresLine(source, fields)
 		default:
+			if s.unknownLine != nil {
+				s.unknownLine(source)
+				continue
+			}
 			s.err = errors.New("unknown line type")
 		}
 
@@ -137,6 +169,7 @@ func (s *Scanner) Scan() bool {
 			s.err = newParseError(source, s.err)
 			return false
 		}
+		s.count(s.line)
 		return true
 	}
 	s.line = nil // clear line at EOF
@@ -144,6 +177,59 @@ func (s *Scanner) Scan() bool {
 	return false
 }
 
+// checkContinuation reconciles the two signals for zone continuation lines:
+// the UNTIL column of the preceding zone line, which is what zic uses, and
+// the conventional indentation of continuation lines.
+// It returns an error if they disagree.
+func (s *Scanner) checkContinuation(line string, fields []string) error {
+	indented := line[0] == ' ' || line[0] == '\t'
+	keyword := isKeyword(fields[0])
+	switch {
+	case s.zoneContinuationExpected && keyword:
+		return fmt.Errorf("expected zone continuation line after zone line with UNTIL, got %s line", fields[0])
+	case !s.zoneContinuationExpected && indented && !keyword:
+		return errors.New("indented line looks like a zone continuation line, but the preceding line is not a zone line with UNTIL")
+	}
+	return nil
+}
+
+// isKeyword returns true if s is the first field of a rule, zone, link,
+// leap or expires line.
+func isKeyword(s string) bool {
+	switch s {
+	case "Rule", "Zone", "Link", "Leap", "Expires":
+		return true
+	}
+	return false
+}
+
+// count adds the line to the line counts.
+func (s *Scanner) count(l Line) {
+	switch l := l.(type) {
+	case RuleLine:
+		s.counts.Rules++
+	case ZoneLine:
+		if l.Continuation {
+			s.counts.Continuations++
+		} else {
+			s.counts.Zones++
+		}
+	case LinkLine:
+		s.counts.Links++
+	case LeapLine:
+		s.counts.Leaps++
+	case ExpiresLine:
+		s.counts.Expires++
+	}
+}
+
+// Count returns the number of lines of each type that were read so far.
+// After Scan returned false without an error, these are the totals of
+// the input.
+func (s *Scanner) Count() LineCounts {
+	return s.counts
+}
+
 // Err returns the first error that was encountered by the scanner.
 func (s *Scanner) Err() error {
 	return s.err
@@ -473,6 +559,26 @@ type Time struct {
 	Form TimeForm
 }
 
+// FromSecondsOfDay returns the time of the given form that is the given
+// number of seconds after 00:00.
+func FromSecondsOfDay(secs int, form TimeForm) Time {
+	return Time{Duration: time.Duration(secs) * time.Second, Form: form}
+}
+
+// SecondsOfDay returns the number of seconds of the time since 00:00.
+// Fractional seconds are rounded to the nearest second, with ties rounded
+// to even like zic does; for example, 0:29:45.50 yields 1786 seconds.
+func (t Time) SecondsOfDay() int {
+	secs, frac := t.Duration/time.Second, t.Duration%time.Second
+	if frac < 0 {
+		secs, frac = secs-1, frac+time.Second
+	}
+	if frac > time.Second/2 || (frac == time.Second/2 && secs%2 != 0) {
+		secs++
+	}
+	return int(secs)
+}
+
 // Day represents a day in a rule or zone line.
 type Day struct {
 	Form DayForm
@@ -480,6 +586,28 @@ type Day struct {
 	Day  time.Weekday
 }
 
+// Date returns the date that the day denotes in the given year and month,
+// at 00:00 UTC.
+//
+// The "<=" and ">=" forms can result in a day in the neighboring month or
+// even year; for example, "Oct Sun>=31" stands for the first Sunday on or
+// after October 31, even if that Sunday occurs in November.
+func (d Day) Date(year int, month time.Month) time.Time {
+	switch d.Form {
+	case DayFormLast:
+		t := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		return t.AddDate(0, 0, -((int(t.Weekday()) - int(d.Day) + 7) % 7))
+	case DayFormAfter:
+		t := time.Date(year, month, d.Num, 0, 0, 0, 0, time.UTC)
+		return t.AddDate(0, 0, (int(d.Day)-int(t.Weekday())+7)%7)
+	case DayFormBefore:
+		t := time.Date(year, month, d.Num, 0, 0, 0, 0, time.UTC)
+		return t.AddDate(0, 0, -((int(t.Weekday()) - int(d.Day) + 7) % 7))
+	default:
+		return time.Date(year, month, d.Num, 0, 0, 0, 0, time.UTC)
+	}
+}
+
 // RuleLine represents a rule line.
 type RuleLine struct {
 	lineInFile
@@ -622,8 +750,50 @@ func parseZoneFORMAT(s string) (string, error) {
 	if len(s) == 0 {
 		return "", fmt.Errorf("empty format")
 	}
-	unquoted, _ := unquote(s)
-	return unquoted, nil
+	return s, nil
+}
+
+// Abbreviation returns the time zone abbreviation that the FORMAT column of
+// the zone line yields when a rule with the given LETTER/S and SAVE columns
+// is in effect. For zone lines without rules, letters is empty and save is
+// the zero Time or the time in the RULES column.
+//
+// If the format contains a slash, the part before it is used for standard
+// time and the part after it for daylight saving time, as given by the form
+// of save. Otherwise, %s is replaced by letters and %z by the UT offset.
+// Slashes in letters have no special meaning.
+func (z ZoneLine) Abbreviation(letters string, save Time) string {
+	if std, dst, ok := strings.Cut(z.Format, "/"); ok {
+		if save.Form == DaylightSavingTime {
+			return dst
+		}
+		return std
+	}
+	abbr := strings.Replace(z.Format, "%s", letters, 1)
+	if strings.Contains(abbr, "%z") {
+		abbr = strings.Replace(abbr, "%z", formatUTOffset(z.Offset+save.Duration), 1)
+	}
+	return abbr
+}
+
+// formatUTOffset formats a UT offset as ±hh, ±hhmm, or ±hhmmss, using the
+// shortest form that does not lose information.
+func formatUTOffset(d time.Duration) string {
+	sign := '+'
+	if d < 0 {
+		sign = '-'
+		d = -d
+	}
+	secs := int(d / time.Second)
+	h, m, sec := secs/3600, secs/60%60, secs%60
+	switch {
+	case sec != 0:
+		return fmt.Sprintf("%c%02d%02d%02d", sign, h, m, sec)
+	case m != 0:
+		return fmt.Sprintf("%c%02d%02d", sign, h, m)
+	default:
+		return fmt.Sprintf("%c%02d", sign, h)
+	}
 }
 
 // UntilPartsMask is a bitmask of the parts that are defined in the UNTIL column of a zone line.
@@ -685,6 +855,38 @@ type Until struct {
 	Time Time
 }
 
+// Instant returns the instant in UT that the UNTIL column denotes, given
+// the standard UT offset and the amount of daylight saving time in effect
+// just before it, which is how the spec says the column is interpreted.
+//
+// Omitted fields default to the earliest possible value. A wall clock time
+// is converted by subtracting both stdoff and save, a standard time by
+// subtracting stdoff only, and a universal time is returned as is.
+// The zero time is returned if the column is not defined.
+func (u Until) Instant(stdoff, save time.Duration) time.Time {
+	if !u.Defined {
+		return time.Time{}
+	}
+	month, day := time.January, Day{Form: DayFormDayNum, Num: 1}
+	if u.Parts.Has(untilMonthOnly) {
+		month = u.Month
+	}
+	if u.Parts.Has(untilDayOnly) {
+		day = u.Day
+	}
+	t := day.Date(u.Year, month)
+	if u.Parts.Has(untilTimeOnly) {
+		t = t.Add(u.Time.Duration)
+		switch u.Time.Form {
+		case UniversalTime:
+			return t
+		case StandardTime:
+			return t.Add(-stdoff)
+		}
+	}
+	return t.Add(-stdoff - save)
+}
+
 // parseZoneUNTIL parses the UNTIL column of a zone line.
 // It returns an error if the column is invalid according to spec.
 //
@@ -796,7 +998,7 @@ func parseZoneLine(source lineInFile, fields []string) (ZoneLine, error) {
 	if len(fields) > 5 {
 		until := strings.Join(fields[5:], " ")
 		if z.Until, err = parseZoneUNTIL(until); err != nil {
-			errs = errors.Join(errs, fmt.Errorf("UNTIL %q: %w", fields[5], err))
+			errs = errors.Join(errs, fmt.Errorf("UNTIL %q: %w", until, err))
 		}
 	}
 	return z, errs
@@ -814,7 +1016,7 @@ func parseZoneLine(source lineInFile, fields []string) (ZoneLine, error) {
 //	previous line.  Continuation lines may contain “until”
 //	information, just as zone lines do, indicating that the
 //	next line is a further continuation.
-func parseZoneContinuationLine(fields []string) (ZoneLine, error) {
+func parseZoneContinuationLine(source lineInFile, fields []string) (ZoneLine, error) {
 	if len(fields) < 3 {
 		return ZoneLine{}, fmt.Errorf("expected at least 3 fields, got %d", len(fields))
 	}
@@ -822,7 +1024,7 @@ func parseZoneContinuationLine(fields []string) (ZoneLine, error) {
 		return ZoneLine{}, fmt.Errorf("expected at most 7 fields, got %d", len(fields))
 	}
 	var (
-		z    ZoneLine
+		z    = ZoneLine{lineInFile: source}
 		errs error
 		err  error
 	)
@@ -839,7 +1041,7 @@ func parseZoneContinuationLine(fields []string) (ZoneLine, error) {
 	if len(fields) > 3 {
 		until := strings.Join(fields[3:], " ")
 		if z.Until, err = parseZoneUNTIL(until); err != nil {
-			errs = errors.Join(errs, fmt.Errorf("UNTIL %q: %w", fields[2], err))
+			errs = errors.Join(errs, fmt.Errorf("UNTIL %q: %w", until, err))
 		}
 	}
 	return z, errs
@@ -877,6 +1079,9 @@ func parseRuleLine(source lineInFile, fields []string) (RuleLine, error) {
 	if r.To, err = parseRuleTO(fields[3], r.From); err != nil {
 		errs = errors.Join(errs, fmt.Errorf("TO %q: %w", fields[3], err))
 	}
+	if err = parseRuleTYPE(fields[4]); err != nil {
+		errs = errors.Join(errs, fmt.Errorf("TYPE %q: %w", fields[4], err))
+	}
 	if r.In, err = parseRuleIN(fields[5]); err != nil {
 		errs = errors.Join(errs, fmt.Errorf("IN %q: %w", fields[5], err))
 	}
@@ -912,45 +1117,43 @@ func parseRuleLine(source lineInFile, fields []string) (RuleLine, error) {
 //	stripping) is ignored.  Nonblank lines are expected to be of one
 //	of three types: rule lines, zone lines, and link lines.
 func splitLine(line string) ([]string, error) {
-	// Remove comments.
-	i := strings.Index(line, "#")
-	if i != -1 {
-		line = line[:i]
-	}
-	// Remove leading and trailing white space.
-	line = strings.TrimSpace(line)
-
-	if len(line) == 0 {
-		return nil, nil
-	}
-
-	return strings.Fields(line), nil
-
-	//// Split fields, respecting quoted fields.
-	//var fields []string
-	//for len(line) > 0 {
-	//	if line[0] == '"' {
-	//		// Quoted field.
-	//		i := strings.Index(line[1:], `"`)
-	//		if i == -1 {
-	//			// No closing quote.
-	//			return nil, fmt.Errorf("no closing quote: %q", line)
-	//		}
-	//		fields = append(fields, line[1:i+1])
-	//		line = line[i+2:]
-	//	} else {
-	//		// Unquoted field.
-	//		i := strings.IndexAny(line, " \f\r\n\t\v")
-	//		if i == -1 {
-	//			// Last field.
-	//			fields = append(fields, line)
-	//			break
-	//		}
-	//		fields = append(fields, line[:i])
-	//		line = line[i+1:]
-	//	}
-	//}
-	//return fields, nil
+	var (
+		fields  []string
+		field   strings.Builder
+		inField bool
+		quoted  bool
+	)
+scan:
+	for _, c := range line {
+		switch {
+		case c == '"':
+			// Like zic, drop the quotes, which may also enclose only a
+			// part of a field.
+			quoted = !quoted
+			inField = true
+		case quoted:
+			field.WriteRune(c)
+		case c == '#':
+			// Comment until the end of the line.
+			break scan
+		case strings.ContainsRune(" \f\r\n\t\v", c):
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			inField = true
+			field.WriteRune(c)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("no closing quote: %q", line)
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
 }
 
 // parseRuleNAME parses the NAME column of a rule.
@@ -963,6 +1166,9 @@ func splitLine(line string) ([]string, error) {
 //	ASCII digit nor “-” nor “+”.  To allow for future
 //	extensions, an unquoted name should not contain characters
 //	from the set “!$%&'()*,/:;<=>?@[\]^`{|}~”.
+//
+// The quotes are already removed by splitLine, so like zic, the
+// recommendation for unquoted names is not enforced.
 func parseRuleNAME(s string) (string, error) {
 	if len(s) == 0 {
 		return "", fmt.Errorf("empty name")
@@ -974,35 +1180,7 @@ func parseRuleNAME(s string) (string, error) {
 		return "", fmt.Errorf("name starts with a sign: %q", s)
 	}
 
-	unquoted, wasQuoted := unquote(s)
-	if !wasQuoted {
-		// unquoted name
-		if containsSpecialChar(s) {
-			return "", fmt.Errorf("name contains special character: %q", s)
-		}
-	}
-	return unquoted, nil
-}
-
-// containsSpecialChar returns true if the string contains any of the special characters
-func containsSpecialChar(s string) bool {
-	specialChars := "!$%&'()*,/:;<=>?@[\\]^`{|}~"
-	for _, char := range specialChars {
-		if strings.ContainsRune(s, char) {
-			return true
-		}
-	}
-	return false
-}
-
-// unquote removes quotes from a string.
-// It returns the unquoted string and true if the string was quoted.
-// Otherwise, it returns the original string and false.
-func unquote(s string) (string, bool) {
-	if s[0] == '"' && s[len(s)-1] == '"' {
-		return s[1 : len(s)-1], true
-	}
-	return s, false
+	return s, nil
 }
 
 // parseRuleFROM parses the FROM columns a rule.
@@ -1059,6 +1237,22 @@ func parseRuleTO(s string, from Year) (Year, error) {
 	return Year(n), nil
 }
 
+// parseRuleTYPE parses the reserved TYPE column of a rule.
+// It returns an error unless the column is "-", since year types are not
+// supported.
+//
+// The spec says:
+//
+//	Should be “-” and is present for compatibility with older
+//	versions of zic in which it contained the obsolete TYPE
+//	field.
+func parseRuleTYPE(s string) error {
+	if s != "-" && s != `""` {
+		return errors.New(`year types are obsolete; use "-" instead`)
+	}
+	return nil
+}
+
 // parseRuleON parses the IN columns of a rule.
 // It returns an error if the day is invalid according to spec.
 //
@@ -1170,6 +1364,13 @@ func parseRuleON(s string) (Day, error) {
 	return Day{}, fmt.Errorf("invalid")
 }
 
+// ParseDayField parses the text of an ON column of a rule line or the
+// day of an UNTIL column of a zone line, such as "15", "lastSun",
+// "Sun>=8" or "Sun<=25".
+func ParseDayField(s string) (Day, error) {
+	return parseRuleON(s)
+}
+
 // parseRuleAT parses the AT column of a rule.
 // It returns an error if the time is invalid according to spec.
 //
@@ -1273,13 +1474,13 @@ func parseRuleSAVE(s string) (Time, error) {
 //	“EST” or “EDT”) of time zone abbreviations to be used when
 //	this rule is in effect.  If this field is “-”, the
 //	variable part is null.
+//
+// Unlike the FORMAT column of zone lines, a slash in the LETTER/S column
+// has no special meaning; it is part of the variable part.
 func parseRuleLETTERS(s string) (string, error) {
 	if len(s) == 0 {
 		return "", fmt.Errorf("empty letter")
 	}
-	if s[0] == '"' && s[len(s)-1] == '"' {
-		s = s[1 : len(s)-1]
-	}
 	if s == "-" {
 		return "", nil
 	}
@@ -1327,10 +1528,16 @@ func parseTimeOfDay(s string) (time.Duration, error) {
 		return 0, nil // Equivalent to 0 duration.
 	}
 
-	// Handle negative time.
+	// Handle negative time. An explicit positive sign is accepted and
+	// ignored, as in offsets derived from %z.
 	isNegative := strings.HasPrefix(s, "-")
 	if isNegative {
 		s = strings.TrimPrefix(s, "-")
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		return 0, fmt.Errorf("invalid sign in %q", s)
 	}
 
 	// Split the time into components.
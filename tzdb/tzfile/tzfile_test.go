@@ -2,7 +2,9 @@ package tzfile
 
 import (
 	"bytes"
+	"errors"
 	"os"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -13,8 +15,8 @@ import (
 	"github.com/go-tz/tz/tzdb/ianadist"
 )
 
-func TestScanner_ExtendedExample(t *testing.T) {
-	var input = strings.TrimSpace(`
+// extendedExample is the extended example of the zic man page.
+var extendedExample = strings.TrimSpace(`
 # Rule  NAME  FROM  TO    -  IN   ON       AT    SAVE  LETTER/S
 Rule    Swiss 1941  1942  -  May  Mon>=1   1:00  1:00  S
 Rule    Swiss 1941  1942  -  Oct  Mon>=1   2:00  0     -
@@ -33,6 +35,8 @@ Zone    Europe/Zurich  0:34:08     -      LMT     1853 Jul 16
 
 Link    Europe/Zurich  Europe/Vaduz
 `)
+
+func TestScanner_ExtendedExample(t *testing.T) {
 	want := []Line{
 		RuleLine{Name: "Swiss", From: 1941, To: 1942, In: time.May, On: Day{Form: DayFormAfter, Day: time.Monday, Num: 1}, At: Time{Duration: 1 * time.Hour, Form: WallClock}, Save: Time{Duration: 1 * time.Hour, Form: DaylightSavingTime}, Letter: "S"},
 		RuleLine{Name: "Swiss", From: 1941, To: 1942, In: time.October, On: Day{Form: DayFormAfter, Day: time.Monday, Num: 1}, At: Time{Duration: 2 * time.Hour, Form: WallClock}, Save: Time{Duration: 0, Form: StandardTime}, Letter: ""},
@@ -50,7 +54,7 @@ Link    Europe/Zurich  Europe/Vaduz
 	}
 
 	var got []Line
-	s := NewScanner(strings.NewReader(input))
+	s := NewScanner(strings.NewReader(extendedExample))
 	for s.Scan() {
 		got = append(got, s.Line())
 	}
@@ -61,6 +65,11 @@ Link    Europe/Zurich  Europe/Vaduz
 	if diff := cmp.Diff(want, got, cmpopts.IgnoreTypes(lineInFile{})); diff != "" {
 		t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
 	}
+
+	wantCounts := LineCounts{Rules: 8, Zones: 1, Continuations: 3, Links: 1}
+	if diff := cmp.Diff(wantCounts, s.Count()); diff != "" {
+		t.Errorf("Count() mismatch (-want +got):\n%s", diff)
+	}
 }
 
 func TestScanner_Leap(t *testing.T) {
@@ -113,4 +122,512 @@ func TestIANADataIntegration(t *testing.T) {
 			}
 		})
 	}
+
+	// backzone holds zones for names that backward defines as links,
+	// so the two are not combined.
+	delete(files.DataFiles, "backzone")
+	files.DataFiles["backward"] = files.BackwardFile
+	f, err := ParseFiles(files.DataFiles, ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseFiles() error: %v", err)
+	}
+	deprecated := f.DeprecatedNames()
+	for _, name := range []string{"America/Buenos_Aires", "Asia/Calcutta"} {
+		if i := sort.SearchStrings(deprecated, name); i == len(deprecated) || deprecated[i] != name {
+			t.Errorf("DeprecatedNames() does not contain %q", name)
+		}
+	}
+}
+
+func TestParse_IANALeapSeconds(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/tzdata-2024b.tar.gz")
+	if err != nil {
+		t.Fatal("failed to read test data file:", err)
+	}
+	release, err := ianadist.ReadArchive(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal("failed to read tzdata archive:", err)
+	}
+
+	f, err := Parse(bytes.NewReader(release.LeapSecondsFile))
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	// 27 leap seconds were inserted from 1972 through 2016.
+	if got := len(f.Leaps); got != 27 {
+		t.Errorf("len(Leaps) = %d, want 27", got)
+	}
+	// The Expires line of 2024b is commented out ("#Expires 2025 Jun 28"),
+	// so the file has no expiration for zic.
+	if f.Expires != nil {
+		t.Errorf("Expires = %+v, want nil", f.Expires)
+	}
+	if len(f.Rules) != 0 || len(f.Zones) != 0 || len(f.Links) != 0 {
+		t.Errorf("Parse() = %d rules, %d zones, %d links, want only leap and expires lines", len(f.Rules), len(f.Zones), len(f.Links))
+	}
+}
+
+func TestParseRuleSAVE(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Time
+	}{
+		{"0", Time{Duration: 0, Form: StandardTime}},
+		{"1:00", Time{Duration: time.Hour, Form: DaylightSavingTime}},
+		// Double summer time, as observed in Britain during the Second World War.
+		{"2:00", Time{Duration: 2 * time.Hour, Form: DaylightSavingTime}},
+		{"-1:00", Time{Duration: -time.Hour, Form: DaylightSavingTime}},
+		{"0:30s", Time{Duration: 30 * time.Minute, Form: StandardTime}},
+	}
+	for _, tt := range tests {
+		got, err := parseRuleSAVE(tt.in)
+		if err != nil {
+			t.Errorf("parseRuleSAVE(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseRuleSAVE(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScanner_RuleTYPE(t *testing.T) {
+	s := NewScanner(strings.NewReader("Rule US 1968 1972 uspres Apr lastSun 2:00 1:00 D\n"))
+	if s.Scan() {
+		t.Fatalf("Scan() = true, want false for obsolete year type")
+	}
+	if err := s.Err(); err == nil || !strings.Contains(err.Error(), `TYPE "uspres": year types are obsolete`) {
+		t.Errorf("Err() = %v, want TYPE error", err)
+	}
+}
+
+func TestScanner_ContinuationMismatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "indented line after zone line without UNTIL",
+			input: "Zone\tEurope/Zurich\t1:00\t-\tCET\n" +
+				"\t\t\t1:00\tEU\tCE%sT\n",
+			want: "indented line looks like a zone continuation line",
+		},
+		{
+			name: "keyword after zone line with UNTIL",
+			input: "Zone\tEurope/Zurich\t1:00\t-\tCET\t1981\n" +
+				"Link\tEurope/Zurich\tEurope/Vaduz\n",
+			want: "expected zone continuation line after zone line with UNTIL, got Link line",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner(strings.NewReader(tt.input))
+			for s.Scan() {
+			}
+			var perr ParseError
+			if err := s.Err(); !errors.As(err, &perr) || perr.Source.LineNum() != 2 || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Err() = %v, want error on line 2 containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_CommentBeforeContinuation(t *testing.T) {
+	input := "Zone\tEurope/Zurich\t0:34:08\t-\tLMT\t1853 Jul 16\n" +
+		"# Bern Mean Time, see the commentary above.\n" +
+		"\n" +
+		"\t# An indented comment.\n" +
+		"\t\t\t0:29:46\t-\tBMT\t1894 Jun\n" +
+		"\t\t\t1:00\tSwiss\tCE%sT\n" +
+		"Link\tEurope/Zurich\tEurope/Vaduz\n"
+	s := NewScanner(strings.NewReader(input))
+	var got []Line
+	for s.Scan() {
+		got = append(got, s.Line())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("scanned %d lines, want 4", len(got))
+	}
+	for i, want := range []bool{false, true, true} {
+		z, ok := got[i].(ZoneLine)
+		if !ok || z.Continuation != want {
+			t.Errorf("line %d = %#v, want zone line with Continuation %t", i, got[i], want)
+		}
+	}
+	if z := got[1].(ZoneLine); z.Format != "BMT" || z.LineNum() != 5 {
+		t.Errorf("continuation line = %q on line %d, want BMT on line 5", z.Format, z.LineNum())
+	}
+	if _, ok := got[3].(LinkLine); !ok {
+		t.Errorf("line 3 = %#v, want link line", got[3])
+	}
+}
+
+func TestZoneLine_Abbreviation(t *testing.T) {
+	std := Time{Form: StandardTime}
+	dst := Time{Duration: time.Hour, Form: DaylightSavingTime}
+	tests := []struct {
+		format  string
+		offset  time.Duration
+		letters string
+		save    Time
+		want    string
+	}{
+		{"CE%sT", time.Hour, "", std, "CET"},
+		{"CE%sT", time.Hour, "S", dst, "CEST"},
+		{"%s", time.Hour, "S", dst, "S"},
+		{"GMT/BST", 0, "", std, "GMT"},
+		{"GMT/BST", 0, "", dst, "BST"},
+		// The letters do not select a half of a slash format.
+		{"GMT/BST", 0, "S", dst, "BST"},
+		// A slash in the letters is part of the variable part.
+		{"%s", 0, "S/D", dst, "S/D"},
+		{"%z", -3 * time.Hour, "", std, "-03"},
+		{"%z", 5*time.Hour + 30*time.Minute, "", dst, "+0630"},
+		{"%z", 34*time.Minute + 8*time.Second, "", std, "+003408"},
+	}
+	for _, tt := range tests {
+		z := ZoneLine{Format: tt.format, Offset: tt.offset}
+		if got := z.Abbreviation(tt.letters, tt.save); got != tt.want {
+			t.Errorf("ZoneLine{Format: %q}.Abbreviation(%q, %v) = %q, want %q", tt.format, tt.letters, tt.save, got, tt.want)
+		}
+	}
+}
+
+func TestZoneLine_Abbreviation_EmptyLetters(t *testing.T) {
+	input := "Rule\tEU\t1981\tmax\t-\tOct\tlastSun\t1:00u\t0\t-\n" +
+		"Zone\tEurope/Zurich\t1:00\tEU\tCE%sT\n" +
+		"Zone\tEtc/Empty\t1:00\tEU\t%s\n"
+	f, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := f.Rules[0]
+	if r.Letter != "" {
+		t.Fatalf("LETTER %q parsed as %q, want empty", "-", r.Letter)
+	}
+	// An empty variable part leaves the rest of the format, and a format
+	// that is only the variable part yields an empty designation, which
+	// zic allows.
+	for i, want := range []string{"CET", ""} {
+		if got := f.Zones[i].Abbreviation(r.Letter, r.Save); got != want {
+			t.Errorf("%s: Abbreviation(%q, %v) = %q, want %q", f.Zones[i].Name, r.Letter, r.Save, got, want)
+		}
+	}
+}
+
+func TestParseZoneSTDOFF_OddSeconds(t *testing.T) {
+	// The LMT offset of Pacific/Honolulu is not a whole number of minutes.
+	got, err := parseZoneSTDOFF("-10:31:26")
+	if want := -37886 * time.Second; err != nil || got != want {
+		t.Errorf("parseZoneSTDOFF(%q) = %v, %v, want %v, nil", "-10:31:26", got, err, want)
+	}
+}
+
+func TestParseTimeOfDay_PositiveSign(t *testing.T) {
+	if got, err := parseZoneSTDOFF("+1:00"); err != nil || got != time.Hour {
+		t.Errorf("parseZoneSTDOFF(%q) = %v, %v, want %v, nil", "+1:00", got, err, time.Hour)
+	}
+	want := Time{Duration: 2 * time.Hour, Form: UniversalTime}
+	if got, err := parseRuleAT("+2:00u"); err != nil || got != want {
+		t.Errorf("parseRuleAT(%q) = %+v, %v, want %+v, nil", "+2:00u", got, err, want)
+	}
+	if _, err := parseZoneSTDOFF("+-1:00"); err == nil {
+		t.Errorf("parseZoneSTDOFF(%q) = nil error, want error", "+-1:00")
+	}
+}
+
+func TestParseZoneUNTIL_UniversalTime(t *testing.T) {
+	for _, suffix := range []string{"u", "g", "z"} {
+		s := "1994 Jun 1 2:00" + suffix
+		want := Until{
+			Defined: true,
+			Year:    1994,
+			Month:   time.June,
+			Day:     Day{Form: DayFormDayNum, Num: 1},
+			Time:    Time{Duration: 2 * time.Hour, Form: UniversalTime},
+			Parts:   UntilTime,
+		}
+		got, err := parseZoneUNTIL(s)
+		if err != nil {
+			t.Fatalf("parseZoneUNTIL(%q) error: %v", s, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("parseZoneUNTIL(%q) mismatch (-want +got):\n%s", s, diff)
+		}
+	}
+}
+
+func TestScanner_TrailingFieldsAfterUNTIL(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "zone line",
+			input: "Zone\tEurope/Zurich\t1:00\t-\tCET\t1981 Jan 1 0:00 extra\n",
+			want:  "expected at most 9 fields, got 10",
+		},
+		{
+			name: "continuation line",
+			input: "Zone\tEurope/Zurich\t0:34:08\t-\tLMT\t1853 Jul 16\n" +
+				"\t\t\t0:29:46\t-\tBMT\t1894 Jun 1 0:00 extra\n",
+			want: "expected at most 7 fields, got 8",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner(strings.NewReader(tt.input))
+			for s.Scan() {
+			}
+			if err := s.Err(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Err() = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUntil_Instant(t *testing.T) {
+	// Central European Summer Time: a standard offset of one hour and one
+	// hour of saving in effect just before the UNTIL.
+	const (
+		stdoff = time.Hour
+		save   = time.Hour
+	)
+	tests := []struct {
+		until string
+		want  time.Time
+	}{
+		{"1894 Jun", time.Date(1894, time.May, 31, 22, 0, 0, 0, time.UTC)},
+		{"1996 Mar lastSun 2:00", time.Date(1996, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		{"1996 Mar lastSun 2:00s", time.Date(1996, time.March, 31, 1, 0, 0, 0, time.UTC)},
+		{"1996 Mar lastSun 2:00u", time.Date(1996, time.March, 31, 2, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		u, err := parseZoneUNTIL(tt.until)
+		if err != nil {
+			t.Fatalf("parseZoneUNTIL(%q) error: %v", tt.until, err)
+		}
+		if got := u.Instant(stdoff, save); !got.Equal(tt.want) {
+			t.Errorf("Until(%q).Instant() = %v, want %v", tt.until, got, tt.want)
+		}
+	}
+	if got := (Until{}).Instant(stdoff, save); !got.IsZero() {
+		t.Errorf("Until{}.Instant() = %v, want zero time", got)
+	}
+}
+
+func TestParseDayField(t *testing.T) {
+	tests := map[string]Day{
+		"lastSun": {Form: DayFormLast, Day: time.Sunday},
+		"Sun>=8":  {Form: DayFormAfter, Day: time.Sunday, Num: 8},
+		"Sun<=25": {Form: DayFormBefore, Day: time.Sunday, Num: 25},
+		"15":      {Form: DayFormDayNum, Num: 15},
+	}
+	for in, want := range tests {
+		got, err := ParseDayField(in)
+		if err != nil {
+			t.Errorf("ParseDayField(%q) error: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseDayField(%q) = %+v, want %+v", in, got, want)
+		}
+	}
+	if _, err := ParseDayField("Sun>8"); err == nil {
+		t.Errorf("ParseDayField(%q) = nil error, want error", "Sun>8")
+	}
+}
+
+func TestDay_Date(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		on    string
+		year  int
+		month time.Month
+		want  time.Time
+	}{
+		{"15", 2022, time.October, date(2022, time.October, 15)},
+		{"lastSun", 2022, time.October, date(2022, time.October, 30)},
+		{"lastSun", 2021, time.October, date(2021, time.October, 31)},
+		{"Sun>=8", 2022, time.March, date(2022, time.March, 13)},
+		{"Sun<=25", 2022, time.March, date(2022, time.March, 20)},
+		// The example of the spec: the first Sunday on or after October 31
+		// occurs in November if October 31 is not a Sunday.
+		{"Sun>=31", 2021, time.October, date(2021, time.October, 31)},
+		{"Sun>=31", 2022, time.October, date(2022, time.November, 6)},
+		// Carry into the next and previous year.
+		{"Sun>=31", 2022, time.December, date(2023, time.January, 1)},
+		{"Sat<=1", 2023, time.January, date(2022, time.December, 31)},
+	}
+	for _, tt := range tests {
+		d, err := ParseDayField(tt.on)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.Date(tt.year, tt.month); !got.Equal(tt.want) {
+			t.Errorf("%s %d %q: Date() = %v, want %v", tt.month, tt.year, tt.on, got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestScanner_FactoryAndGMT(t *testing.T) {
+	input := `Zone	Factory	0	-	"Local time zone must be set--see zic manual page" # old style
+Zone	Factory	0	-	-00
+Zone	Etc/GMT	0	-	GMT
+Rule	"Has space"	1970	only	-	Jan	1	0	0	"#"
+`
+	want := []Line{
+		ZoneLine{Name: "Factory", Rules: ZoneRules{Form: ZoneRulesStandard}, Format: "Local time zone must be set--see zic manual page"},
+		ZoneLine{Name: "Factory", Rules: ZoneRules{Form: ZoneRulesStandard}, Format: "-00"},
+		ZoneLine{Name: "Etc/GMT", Rules: ZoneRules{Form: ZoneRulesStandard}, Format: "GMT"},
+		RuleLine{Name: "Has space", From: 1970, To: 1970, In: time.January, On: Day{Form: DayFormDayNum, Num: 1}, At: Time{Form: WallClock}, Save: Time{Form: StandardTime}, Letter: "#"},
+	}
+	var got []Line
+	s := NewScanner(strings.NewReader(input))
+	for s.Scan() {
+		got = append(got, s.Line())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreTypes(lineInFile{})); diff != "" {
+		t.Errorf("Scan() mismatch (-want +got):\n%s", diff)
+	}
+
+	s = NewScanner(strings.NewReader(`Zone Factory 0 - "unterminated` + "\n"))
+	if s.Scan() || s.Err() == nil {
+		t.Errorf("Scan() of unterminated quote: err = %v, want error", s.Err())
+	}
+}
+
+func TestScanner_QuotedNames(t *testing.T) {
+	input := `Rule	"EU(old)"	1977	1980	-	Apr	Sun>=1	1:00u	1:00	"S"
+Zone	"Etc/UTC"	0	-	UTC
+Zone	Etc/"Quoted Zone"	0	"EU(old)"	Q"u o"T
+Link	"Etc/UTC"	"UTC"
+`
+	want := []Line{
+		RuleLine{Name: "EU(old)", From: 1977, To: 1980, In: time.April, On: Day{Form: DayFormAfter, Day: time.Sunday, Num: 1},
+			At: Time{Duration: time.Hour, Form: UniversalTime}, Save: Time{Duration: time.Hour, Form: DaylightSavingTime}, Letter: "S"},
+		ZoneLine{Name: "Etc/UTC", Rules: ZoneRules{Form: ZoneRulesStandard}, Format: "UTC"},
+		ZoneLine{Name: "Etc/Quoted Zone", Rules: ZoneRules{Form: ZoneRulesName, Name: "EU(old)"}, Format: "Qu oT"},
+		LinkLine{From: "Etc/UTC", To: "UTC"},
+	}
+	var got []Line
+	s := NewScanner(strings.NewReader(input))
+	for s.Scan() {
+		got = append(got, s.Line())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreTypes(lineInFile{})); diff != "" {
+		t.Errorf("Scan() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestScanner_RuleWhitespace(t *testing.T) {
+	inputs := []string{
+		"Rule EU 1981 max - Mar lastSun 1:00u 1:00 S",
+		"Rule\tEU\t1981\tmax\t-\tMar\tlastSun\t1:00u\t1:00\tS",
+		"Rule\tEU\t1981\tmax\t-\tMar\tlastSun\t 1:00u\t1:00\tS   \t",
+		"  Rule    EU    1981  max   -  Mar  lastSun  1:00u 1:00  S # aligned",
+	}
+	var want RuleLine
+	for i, input := range inputs {
+		s := NewScanner(strings.NewReader(input))
+		if !s.Scan() {
+			t.Fatalf("Scan(%q) = false, error: %v", input, s.Err())
+		}
+		got, ok := s.Line().(RuleLine)
+		if !ok {
+			t.Fatalf("Scan(%q) = %T, want RuleLine", input, s.Line())
+		}
+		if i == 0 {
+			want = got
+			continue
+		}
+		if diff := cmp.Diff(want, got, cmpopts.IgnoreTypes(lineInFile{})); diff != "" {
+			t.Errorf("Scan(%q) mismatch (-want +got):\n%s", input, diff)
+		}
+	}
+}
+
+func TestTime_SecondsOfDay(t *testing.T) {
+	tests := []struct {
+		at   string
+		secs int
+	}{
+		{"1:00", 3600},
+		{"2:30", 9000},
+		{"24:00", 86400},
+		{"0:29:45.50", 1786},
+		{"0:29:44.50", 1784},
+		{"0:29:44.51", 1785},
+		{"-0:29:45.50", -1786},
+	}
+	for _, tt := range tests {
+		at, err := parseRuleAT(tt.at)
+		if err != nil {
+			t.Fatalf("parseRuleAT(%q) error: %v", tt.at, err)
+		}
+		if got := at.SecondsOfDay(); got != tt.secs {
+			t.Errorf("parseRuleAT(%q).SecondsOfDay() = %d, want %d", tt.at, got, tt.secs)
+		}
+		if !strings.Contains(tt.at, ".") {
+			if got := FromSecondsOfDay(tt.secs, at.Form); got != at {
+				t.Errorf("FromSecondsOfDay(%d) = %+v, want %+v", tt.secs, got, at)
+			}
+		}
+	}
+}
+
+func TestParseZoneNAME(t *testing.T) {
+	for _, name := range []string{"America/Argentina/Buenos_Aires", "Etc/GMT+5", "Etc/GMT-14", "EST5EDT"} {
+		if got, err := parseZoneNAME(name); err != nil || got != name {
+			t.Errorf("parseZoneNAME(%q) = %q, %v, want %q, nil", name, got, err, name)
+		}
+	}
+	for _, name := range []string{"", "Europe/./Zurich", "../Zurich"} {
+		if _, err := parseZoneNAME(name); err == nil {
+			t.Errorf("parseZoneNAME(%q) = nil error, want error", name)
+		}
+	}
+
+	s := NewScanner(strings.NewReader("Zone\tEtc/GMT+5\t-5\t-\t-05\n"))
+	if !s.Scan() {
+		t.Fatal(s.Err())
+	}
+	if z := s.Line().(ZoneLine); z.Name != "Etc/GMT+5" || z.Offset != -5*time.Hour {
+		t.Errorf("Scan() = %+v, want zone Etc/GMT+5 at -5:00", z)
+	}
+}
+
+func TestLines_Comparable(t *testing.T) {
+	input := "Rule\tEU\t1981\tmax\t-\tMar\tlastSun\t1u\t1:00\tS\n" +
+		"Zone\tEurope/Zurich\t1:00\tEU\tCE%sT\n" +
+		"Link\tEurope/Zurich\tEurope/Vaduz\n"
+	var first, second []Line
+	for _, lines := range []*[]Line{&first, &second} {
+		s := NewScanner(strings.NewReader(input))
+		for s.Scan() {
+			*lines = append(*lines, s.Line())
+		}
+		if err := s.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Comparing lines through the interface panics if they are not comparable.
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("line %d: %#v != %#v", i, first[i], second[i])
+		}
+	}
 }
@@ -0,0 +1,258 @@
+// Package tzics renders TZif data as iCalendar time zone components.
+//
+// The iCalendar format is described in [RFC 5545]. A VTIMEZONE component
+// describes a time zone by a set of STANDARD and DAYLIGHT observances,
+// each of which has an onset, UTC offsets and optionally a recurrence rule.
+//
+// [RFC 5545]: https://datatracker.ietf.org/doc/html/rfc5545#section-3.6.5
+package tzics
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-tz/tz/tzif"
+)
+
+// crlf is the line break used in iCalendar objects.
+const crlf = "\r\n"
+
+// localTimeLayout is the layout of a DATE-TIME value in local time.
+const localTimeLayout = "20060102T150405"
+
+// Write writes an RFC 5545 VTIMEZONE component with the given TZID for the
+// given TZif data to w.
+//
+// The observances are derived from the TZ string in the footer of the data.
+// If the TZ string describes daylight saving time, a DAYLIGHT and a STANDARD
+// observance with yearly recurrence rules are written. Their onsets are the
+// earliest transitions from which on the transitions of the data agree with
+// the TZ string, so that the component also covers the recent past.
+// Otherwise, a single STANDARD observance starting at the last transition
+// is written. Earlier transitions are not represented.
+//
+// An error is returned for version 1 data, for data that tzif.Validate
+// rejects, for data with an empty TZ string, and for TZ string rules that
+// cannot be expressed as recurrence rules.
+func Write(w io.Writer, tzid string, d tzif.Data) error {
+	if !d.Version.IsV2Plus() {
+		return fmt.Errorf("unsupported version %v", d.Version)
+	}
+	if err := tzif.Validate(d); err != nil {
+		return fmt.Errorf("invalid data: %w", err)
+	}
+	if len(d.V2Footer.TZString) == 0 {
+		return errors.New("empty TZ string")
+	}
+	z, err := tzif.ParseTZString(string(d.V2Footer.TZString))
+	if err != nil {
+		return fmt.Errorf("parse TZ string %q: %w", d.V2Footer.TZString, err)
+	}
+
+	var observances []observance
+	if z.HasDST() {
+		observances, err = ruleObservances(d.V2Data, z)
+	} else {
+		observances = fixedObservances(d.V2Data, z)
+	}
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("BEGIN:VTIMEZONE" + crlf)
+	sb.WriteString("TZID:" + tzid + crlf)
+	for _, o := range observances {
+		o.writeTo(&sb)
+	}
+	sb.WriteString("END:VTIMEZONE" + crlf)
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// observance is a STANDARD or DAYLIGHT sub-component of a VTIMEZONE.
+type observance struct {
+	dst   bool
+	start time.Time // onset in UTC
+	from  time.Duration
+	to    time.Duration
+	name  string
+	rrule string // empty if the observance does not recur
+}
+
+func (o observance) writeTo(sb *strings.Builder) {
+	kind := "STANDARD"
+	if o.dst {
+		kind = "DAYLIGHT"
+	}
+	sb.WriteString("BEGIN:" + kind + crlf)
+	sb.WriteString("DTSTART:" + o.start.Add(o.from).Format(localTimeLayout) + crlf)
+	sb.WriteString("TZOFFSETFROM:" + formatOffset(o.from) + crlf)
+	sb.WriteString("TZOFFSETTO:" + formatOffset(o.to) + crlf)
+	sb.WriteString("TZNAME:" + o.name + crlf)
+	if o.rrule != "" {
+		sb.WriteString("RRULE:" + o.rrule + crlf)
+	}
+	sb.WriteString("END:" + kind + crlf)
+}
+
+// formatOffset formats a UTC offset as ("+" / "-") hhmm [ss].
+func formatOffset(d time.Duration) string {
+	sign := '+'
+	if d < 0 {
+		sign = '-'
+		d = -d
+	}
+	secs := int(d / time.Second)
+	h, m, s := secs/3600, secs/60%60, secs%60
+	if s != 0 {
+		return fmt.Sprintf("%c%02d%02d%02d", sign, h, m, s)
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, h, m)
+}
+
+// fixedObservances returns the observance of a TZ string without daylight
+// saving time. It starts at the last transition of the block.
+func fixedObservances(b tzif.V2DataBlock, z tzif.TZString) []observance {
+	o := observance{
+		start: time.Unix(0, 0).UTC(),
+		from:  z.StdOffset,
+		to:    z.StdOffset,
+		name:  z.StdName,
+	}
+	if n := len(b.TransitionTimes); n > 0 {
+		o.start = time.Unix(b.TransitionTimes[n-1], 0).UTC()
+		o.from = utoff(b, n-2)
+	}
+	return []observance{o}
+}
+
+// ruleObservances returns the recurring observances of a TZ string with
+// daylight saving time.
+func ruleObservances(b tzif.V2DataBlock, z tzif.TZString) ([]observance, error) {
+	startRule, err := rrule(z.Start)
+	if err != nil {
+		return nil, fmt.Errorf("start rule: %w", err)
+	}
+	endRule, err := rrule(z.End)
+	if err != nil {
+		return nil, fmt.Errorf("end rule: %w", err)
+	}
+
+	// Find the earliest transition from which on all transitions agree
+	// with the TZ string. If there is none, the observances start after
+	// the last transition.
+	since := time.Unix(0, 0).UTC()
+	if n := len(b.TransitionTimes); n > 0 {
+		since = time.Unix(b.TransitionTimes[n-1]+1, 0).UTC()
+		for i := n - 1; i >= 0 && matchesTZString(b, i, z); i-- {
+			since = time.Unix(b.TransitionTimes[i], 0).UTC()
+		}
+	}
+
+	daylight := observance{dst: true, from: z.StdOffset, to: z.DstOffset, name: z.DstName, rrule: startRule}
+	standard := observance{dst: false, from: z.DstOffset, to: z.StdOffset, name: z.StdName, rrule: endRule}
+	for year := since.Year() - 1; daylight.start.IsZero() || standard.start.IsZero(); year++ {
+		for _, t := range tzif.ExpandTZString(z, year) {
+			if t.Time.Before(since) {
+				continue
+			}
+			if t.Dst && daylight.start.IsZero() {
+				daylight.start = t.Time
+			}
+			if !t.Dst && standard.start.IsZero() {
+				standard.start = t.Time
+			}
+		}
+	}
+	if standard.start.Before(daylight.start) {
+		return []observance{standard, daylight}, nil
+	}
+	return []observance{daylight, standard}, nil
+}
+
+// matchesTZString returns true if the i-th transition of the block is one
+// of the transitions described by the TZ string.
+func matchesTZString(b tzif.V2DataBlock, i int, z tzif.TZString) bool {
+	t := time.Unix(b.TransitionTimes[i], 0).UTC()
+	r := b.LocalTimeTypeRecord[b.TransitionTypes[i]]
+	for year := t.Year() - 1; year <= t.Year()+1; year++ {
+		for _, e := range tzif.ExpandTZString(z, year) {
+			if e.Time.Equal(t) && e.Dst == r.Dst && e.Offset == time.Duration(r.Utoff)*time.Second {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// utoff returns the UT offset in effect after the i-th transition of the block.
+// Before the first transition, local time type 0 is in effect.
+func utoff(b tzif.V2DataBlock, i int) time.Duration {
+	typ := 0
+	if i >= 0 {
+		typ = int(b.TransitionTypes[i])
+	}
+	return b.LocalTimeTypeRecord[typ].Utoffset()
+}
+
+// weekdays maps time.Weekday to iCalendar weekday names.
+var weekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// rrule returns a yearly recurrence rule for the given TZ string transition.
+//
+// Transition times outside of [0, 24h) shift the transition to another day.
+// Such shifts are expressed by restricting the shifted weekday to the
+// shifted range of days of the month, which is only possible if the range
+// stays within the month.
+func rrule(t tzif.TZTransition) (string, error) {
+	shift := int(t.Time / (24 * time.Hour))
+	if t.Time < 0 && t.Time%(24*time.Hour) != 0 {
+		shift--
+	}
+	switch t.Form {
+	case tzif.TZMonthWeekDay:
+		if shift == 0 {
+			week := t.Week
+			if week == 5 {
+				week = -1
+			}
+			return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", t.Month, week, weekdays[t.Weekday]), nil
+		}
+		weekday := weekdays[(int(t.Weekday)+shift%7+7)%7]
+		lo, hi := 7*(t.Week-1)+1+shift, 7*t.Week+shift
+		if t.Week == 5 {
+			lo, hi = -7+shift, -1+shift
+		}
+		// The shortest length of the month, which is only shorter in February of common years.
+		n := time.Date(2001, t.Month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		if (t.Week < 5 && (lo < 1 || hi > n)) || (t.Week == 5 && (hi > -1 || lo < -n)) {
+			return "", fmt.Errorf("transition %+v shifts into another month", t)
+		}
+		days := make([]string, 0, 7)
+		for d := lo; d <= hi; d++ {
+			days = append(days, fmt.Sprint(d))
+		}
+		return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%s;BYMONTHDAY=%s", t.Month, weekday, strings.Join(days, ",")), nil
+	case tzif.TZJulianDay:
+		// The day does not depend on leap years unless the shift crosses February 29.
+		common := time.Date(2001, time.January, t.Day+shift, 0, 0, 0, 0, time.UTC)
+		leap := time.Date(2004, time.January, t.Day+shift, 0, 0, 0, 0, time.UTC)
+		if t.Day >= 60 {
+			leap = leap.AddDate(0, 0, 1)
+		}
+		if common.Year() != 2001 || common.Month() != leap.Month() || common.Day() != leap.Day() {
+			return "", fmt.Errorf("transition %+v has no fixed date", t)
+		}
+		return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYMONTHDAY=%d", common.Month(), common.Day()), nil
+	default:
+		day := t.Day + 1 + shift
+		if day < 1 || day > 365 {
+			return "", fmt.Errorf("transition %+v shifts into another year", t)
+		}
+		return fmt.Sprintf("FREQ=YEARLY;BYYEARDAY=%d", day), nil
+	}
+}
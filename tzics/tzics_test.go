@@ -0,0 +1,161 @@
+package tzics
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-tz/tz/tzif"
+)
+
+// mustDecodeTestData decodes a TZif file from the testdata directory.
+func mustDecodeTestData(t *testing.T, name string) tzif.Data {
+	t.Helper()
+	b, err := os.ReadFile("../testdata/zoneinfo/" + name)
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+	d, err := tzif.DecodeData(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to decode testdata: %v", err)
+	}
+	return d
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{
+			name: "Europe/Zurich",
+			want: []string{
+				"BEGIN:VTIMEZONE",
+				"TZID:Europe/Zurich",
+				"BEGIN:DAYLIGHT",
+				"DTSTART:19960331T020000",
+				"TZOFFSETFROM:+0100",
+				"TZOFFSETTO:+0200",
+				"TZNAME:CEST",
+				"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU",
+				"END:DAYLIGHT",
+				"BEGIN:STANDARD",
+				"DTSTART:19961027T030000",
+				"TZOFFSETFROM:+0200",
+				"TZOFFSETTO:+0100",
+				"TZNAME:CET",
+				"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+				"END:STANDARD",
+				"END:VTIMEZONE",
+			},
+		},
+		{
+			name: "Pacific/Honolulu",
+			want: []string{
+				"BEGIN:VTIMEZONE",
+				"TZID:Pacific/Honolulu",
+				"BEGIN:STANDARD",
+				"DTSTART:19470608T020000",
+				"TZOFFSETFROM:-1030",
+				"TZOFFSETTO:-1000",
+				"TZNAME:HST",
+				"END:STANDARD",
+				"END:VTIMEZONE",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := mustDecodeTestData(t, tt.name)
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.name, d); err != nil {
+				t.Fatalf("Write() failed: %v", err)
+			}
+			want := strings.Join(tt.want, "\r\n") + "\r\n"
+			if diff := cmp.Diff(want, buf.String()); diff != "" {
+				t.Errorf("Write() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWrite_Malformed(t *testing.T) {
+	tests := map[string]func(*tzif.Data){
+		"transition type out of range": func(d *tzif.Data) {
+			d.V2Data.TransitionTypes[len(d.V2Data.TransitionTypes)-1] = 200
+		},
+		"no local time types": func(d *tzif.Data) {
+			d.V2Header.Typecnt = 0
+			d.V2Data.LocalTimeTypeRecord = nil
+		},
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, zone := range []string{"Europe/Zurich", "Pacific/Honolulu"} {
+				d := mustDecodeTestData(t, zone)
+				modify(&d)
+				if err := Write(io.Discard, zone, d); err == nil {
+					t.Errorf("Write(%s) = nil error, want error", zone)
+				}
+			}
+		})
+	}
+}
+
+func TestRRule(t *testing.T) {
+	tests := []struct {
+		tzString  string
+		wantStart string
+		wantEnd   string
+	}{
+		{
+			tzString:  "EST5EDT,M3.2.0,M11.1.0",
+			wantStart: "FREQ=YEARLY;BYMONTH=3;BYDAY=2SU",
+			wantEnd:   "FREQ=YEARLY;BYMONTH=11;BYDAY=1SU",
+		},
+		{
+			// The Friday after the fourth Thursday of March.
+			tzString:  "IST-2IDT,M3.4.4/26,M10.5.0",
+			wantStart: "FREQ=YEARLY;BYMONTH=3;BYDAY=FR;BYMONTHDAY=23,24,25,26,27,28,29",
+			wantEnd:   "FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+		},
+		{
+			// The Saturdays before the last Sundays of March and October.
+			tzString:  "<-03>3<-02>,M3.5.0/-2,M10.5.0/-1",
+			wantStart: "FREQ=YEARLY;BYMONTH=3;BYDAY=SA;BYMONTHDAY=-8,-7,-6,-5,-4,-3,-2",
+			wantEnd:   "FREQ=YEARLY;BYMONTH=10;BYDAY=SA;BYMONTHDAY=-8,-7,-6,-5,-4,-3,-2",
+		},
+		{
+			tzString:  "XXX3YYY,J60,300",
+			wantStart: "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=1",
+			wantEnd:   "FREQ=YEARLY;BYYEARDAY=301",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tzString, func(t *testing.T) {
+			z, err := tzif.ParseTZString(tt.tzString)
+			if err != nil {
+				t.Fatalf("ParseTZString() failed: %v", err)
+			}
+			if got, err := rrule(z.Start); err != nil || got != tt.wantStart {
+				t.Errorf("rrule(start) = %q, %v, want %q", got, err, tt.wantStart)
+			}
+			if got, err := rrule(z.End); err != nil || got != tt.wantEnd {
+				t.Errorf("rrule(end) = %q, %v, want %q", got, err, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestRRule_Unsupported(t *testing.T) {
+	// The day after the last Sunday may be in the next month.
+	tr := tzif.TZTransition{Form: tzif.TZMonthWeekDay, Month: time.March, Week: 5, Weekday: time.Sunday, Time: 25 * time.Hour}
+	if got, err := rrule(tr); err == nil {
+		t.Errorf("rrule(%+v) = %q, want error", tr, got)
+	}
+}
@@ -0,0 +1,87 @@
+package tzif
+
+// CoalesceTransitions returns a copy of the data without the transitions
+// that cause no observable change, that is, whose local time type has the
+// same UT offset, DST flag and designation as the one in effect before.
+// Before the first transition, local time type 0 is in effect. The
+// transitions of both data blocks are coalesced and the timecnt fields of
+// the headers are updated. Unused local time types are kept.
+func (d Data) CoalesceTransitions() Data {
+	d.V1Data.TransitionTimes, d.V1Data.TransitionTypes = coalesce(d.V1Data.TransitionTimes, d.V1Data.TransitionTypes, d.V1Data.LocalTimeTypeRecord, d.V1Data.TimeZoneDesignation)
+	d.V1Header.Timecnt = uint32(len(d.V1Data.TransitionTimes))
+	if d.Version.IsV2Plus() {
+		d.V2Data.TransitionTimes, d.V2Data.TransitionTypes = coalesce(d.V2Data.TransitionTimes, d.V2Data.TransitionTypes, d.V2Data.LocalTimeTypeRecord, d.V2Data.TimeZoneDesignation)
+		d.V2Header.Timecnt = uint32(len(d.V2Data.TransitionTimes))
+	}
+	return d
+}
+
+// coalesce returns new slices of the transition times and types without
+// the transitions that do not change the local time type observably.
+func coalesce[T int32 | int64](times []T, types []uint8, records []LocalTimeTypeRecord, designations []byte) ([]T, []uint8) {
+	if len(times) == 0 || len(records) == 0 {
+		return times, types
+	}
+	type observable struct {
+		utoff int32
+		dst   bool
+		name  string
+	}
+	observe := func(typ uint8) observable {
+		if int(typ) >= len(records) {
+			return observable{} // invalid, reported by Validate
+		}
+		r := records[typ]
+		name, _ := designationAt(designations, r.Idx)
+		return observable{r.Utoff, r.Dst, name}
+	}
+
+	var (
+		newTimes []T
+		newTypes []uint8
+		prev     = observe(0)
+	)
+	for i, t := range times {
+		if i >= len(types) {
+			break
+		}
+		if cur := observe(types[i]); cur != prev {
+			newTimes = append(newTimes, t)
+			newTypes = append(newTypes, types[i])
+			prev = cur
+		}
+	}
+	return newTimes, newTypes
+}
+
+// UnreachableTypes returns the indices of the local time type records that
+// no transition refers to, in ascending order. Local time type 0 is always
+// reachable, as it is in effect before the first transition. The version 1
+// data block is used for version 1 data only.
+func (d Data) UnreachableTypes() []int {
+	if d.Version == V1 {
+		return unreachableTypes(d.V1Data.LocalTimeTypeRecord, d.V1Data.TransitionTypes)
+	}
+	return unreachableTypes(d.V2Data.LocalTimeTypeRecord, d.V2Data.TransitionTypes)
+}
+
+// unreachableTypes returns the indices of the records that are neither
+// local time type 0 nor referred to by the transition types.
+func unreachableTypes(records []LocalTimeTypeRecord, types []uint8) []int {
+	reachable := make([]bool, len(records))
+	if len(reachable) > 0 {
+		reachable[0] = true
+	}
+	for _, typ := range types {
+		if int(typ) < len(reachable) {
+			reachable[typ] = true
+		}
+	}
+	var unreachable []int
+	for i, ok := range reachable {
+		if !ok {
+			unreachable = append(unreachable, i)
+		}
+	}
+	return unreachable
+}
@@ -0,0 +1,61 @@
+package tzif
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestData_CoalesceTransitions(t *testing.T) {
+	d := exampleHonolulu()
+	// Add a local time type equivalent to HST (type 5) and two no-op
+	// transitions to it and to HST after the last transition.
+	d.V2Data.LocalTimeTypeRecord = append(d.V2Data.LocalTimeTypeRecord, LocalTimeTypeRecord{Utoff: -36000, Idx: 4})
+	d.V2Data.UTLocalIndicators = append(d.V2Data.UTLocalIndicators, false)
+	d.V2Data.StandardWallIndicators = append(d.V2Data.StandardWallIndicators, false)
+	d.V2Data.TransitionTimes = append(d.V2Data.TransitionTimes, 0, 100000000)
+	d.V2Data.TransitionTypes = append(d.V2Data.TransitionTypes, 6, 5)
+	d.V2Header.Typecnt, d.V2Header.Isutcnt, d.V2Header.Isstdcnt = 7, 7, 7
+	d.V2Header.Timecnt = 9
+	if err := Validate(d); err != nil {
+		t.Fatalf("Validate() before = %v", err)
+	}
+
+	got := d.CoalesceTransitions()
+	want := exampleHonolulu().V2Data.TransitionTimes
+	if diff := cmp.Diff(want, got.V2Data.TransitionTimes); diff != "" {
+		t.Errorf("TransitionTimes mismatch (-want +got):\n%s", diff)
+	}
+	if got.V2Header.Timecnt != 7 {
+		t.Errorf("V2Header.Timecnt = %d, want 7", got.V2Header.Timecnt)
+	}
+	if err := Validate(got); err != nil {
+		t.Errorf("Validate() after = %v", err)
+	}
+
+	// The resolution of local time is unchanged.
+	from, to := time.Unix(-3000000000, 0), time.Unix(200000000, 0)
+	if diff := cmp.Diff(exampleHonolulu().Timeline(from, to), got.Timeline(from, to)); diff != "" {
+		t.Errorf("Timeline() mismatch (-want +got):\n%s", diff)
+	}
+	// The V1 data has no redundant transitions.
+	if diff := cmp.Diff(d.V1Data, got.V1Data); diff != "" {
+		t.Errorf("V1Data mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestData_UnreachableTypes(t *testing.T) {
+	// No transition refers to LMT, local time type 0, which is in effect
+	// before the first transition.
+	d := exampleHonolulu()
+	if got := d.UnreachableTypes(); got != nil {
+		t.Errorf("UnreachableTypes() = %v, want nil", got)
+	}
+
+	// Add a record that no transition refers to.
+	d.V2Data.LocalTimeTypeRecord = append(d.V2Data.LocalTimeTypeRecord, LocalTimeTypeRecord{Utoff: -34200, Idx: 8})
+	if diff := cmp.Diff([]int{6}, d.UnreachableTypes()); diff != "" {
+		t.Errorf("UnreachableTypes() mismatch (-want +got):\n%s", diff)
+	}
+}
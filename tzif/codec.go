@@ -1,8 +1,11 @@
 package tzif
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"time"
 )
 
 // Data represents a TZif file.
@@ -26,7 +29,7 @@ func (d Data) Encode(w io.Writer) error {
 	if err := d.V1Data.Write(w); err != nil {
 		return fmt.Errorf("write v1 data: %w", err)
 	}
-	if d.Version > V1 {
+	if d.Version.IsV2Plus() {
 		if err := d.V2Header.Write(w); err != nil {
 			return fmt.Errorf("write v2 header: %w", err)
 		}
@@ -40,9 +43,168 @@ func (d Data) Encode(w io.Writer) error {
 	return nil
 }
 
+// Equal returns true if d and e encode to the same bytes. Unlike the
+// comparison of their fingerprints, this also compares the encoding, such
+// as the order of the local time type records.
+func (d Data) Equal(e Data) bool {
+	var a, b bytes.Buffer
+	if err := d.Encode(&a); err != nil {
+		return false
+	}
+	if err := e.Encode(&b); err != nil {
+		return false
+	}
+	return bytes.Equal(a.Bytes(), b.Bytes())
+}
+
+// StripV1 returns a copy of the data without version 1 data: the version
+// 1 header has all counts zero and the version 1 data block is empty, like
+// in the example of RFC 8536, Appendix B.3. Readers that support version
+// 2+ ignore the version 1 data anyway. Version 1 data is returned
+// unchanged, as it has nothing else.
+func (d Data) StripV1() Data {
+	if !d.Version.IsV2Plus() {
+		return d
+	}
+	d.V1Header = Header{Version: d.Version}
+	d.V1Data = V1DataBlock{}
+	return d
+}
+
+// EncodeAs writes the given TZif data to the given writer as a file of
+// the given version. The version of the data and its headers is ignored.
+//
+// An error is returned if the data uses features that the target version
+// does not support: TZ string extensions and abbreviations longer than
+// POSIX guarantees to support require V3, and leap second
+// records denoting truncation or expiration require V4. Encoding as V1
+// requires version 1 data to be present, and encoding as V2 or later
+// requires version 2+ data.
+func (d Data) EncodeAs(w io.Writer, v Version) error {
+	if v != V1 && v != V2 && v != V3 && v != V4 {
+		return fmt.Errorf("unsupported version %v", v)
+	}
+	if v == V1 && d.Version.IsV2Plus() && isEmptyHeader(d.V1Header) {
+		return errors.New("cannot encode as V1: no version 1 data")
+	}
+	if v > V1 && (!d.Version.IsV2Plus() || isEmptyHeader(d.V2Header)) {
+		return fmt.Errorf("cannot encode as %v: no version 2+ data", v)
+	}
+	if v > V1 && v < V3 && len(d.V2Footer.TZString) > 0 {
+		z, err := ParseTZString(string(d.V2Footer.TZString))
+		if err != nil {
+			return fmt.Errorf("parse TZ string: %w", err)
+		}
+		if usesTZStringExtensions(z) {
+			return fmt.Errorf("cannot encode as %v: TZ string %q uses extensions", v, d.V2Footer.TZString)
+		}
+		if name, ok := longAbbreviation(z); ok {
+			return fmt.Errorf("cannot encode as %v: abbreviation %q longer than %d characters", v, name, maxPOSIXAbbreviationLen)
+		}
+	}
+	if v > V1 && v < V4 && usesV4LeapSecondRecords(d.V2Data.LeapSecondRecords) {
+		return fmt.Errorf("cannot encode as %v: leap second records denote truncation or expiration", v)
+	}
+
+	d.Version = v
+	d.V1Header.Version = v
+	d.V2Header.Version = v
+	return d.Encode(w)
+}
+
+// usesTZStringExtensions returns true if the TZ string uses the extensions
+// that are only allowed in version 3+ files: transition times outside the
+// POSIX range of 0 through 24 hours.
+func usesTZStringExtensions(z TZString) bool {
+	if !z.HasDST() {
+		return false
+	}
+	for _, t := range []time.Duration{z.Start.Time, z.End.Time} {
+		if t < 0 || t > 24*time.Hour {
+			return true
+		}
+	}
+	return false
+}
+
+// maxPOSIXAbbreviationLen is the maximum length of a time zone abbreviation
+// in a TZ string that every POSIX implementation supports (_POSIX_TZNAME_MAX).
+// Version 3+ readers must support longer abbreviations.
+const maxPOSIXAbbreviationLen = 6
+
+// longAbbreviation returns the first abbreviation of the TZ string that is
+// longer than maxPOSIXAbbreviationLen and true, or false if there is none.
+func longAbbreviation(z TZString) (string, bool) {
+	for _, name := range []string{z.StdName, z.DstName} {
+		if len(name) > maxPOSIXAbbreviationLen {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// usesV4LeapSecondRecords returns true if the leap second records use
+// the features that are only allowed in version 4 files: a first record
+// with a correction other than +1 or -1, or a last record that repeats the
+// correction of the previous one.
+func usesV4LeapSecondRecords(records []V2LeapSecondRecord) bool {
+	n := len(records)
+	if n == 0 {
+		return false
+	}
+	if records[0].Corr != 1 && records[0].Corr != -1 {
+		return true
+	}
+	return n > 1 && records[n-1].Corr == records[n-2].Corr
+}
+
+// ComputeCounts returns the header of a version 2+ data block of the given
+// version, with the counts derived from the lengths of the fields of b.
+// This is useful when assembling data manually, e.g. with
+// BuildDesignations.
+func ComputeCounts(version Version, b V2DataBlock) Header {
+	return Header{
+		Version:  version,
+		Isutcnt:  uint32(len(b.UTLocalIndicators)),
+		Isstdcnt: uint32(len(b.StandardWallIndicators)),
+		Leapcnt:  uint32(len(b.LeapSecondRecords)),
+		Timecnt:  uint32(len(b.TransitionTimes)),
+		Typecnt:  uint32(len(b.LocalTimeTypeRecord)),
+		Charcnt:  uint32(len(b.TimeZoneDesignation)),
+	}
+}
+
+// ComputeV1Counts is like ComputeCounts for a version 1 data block.
+func ComputeV1Counts(version Version, b V1DataBlock) Header {
+	return Header{
+		Version:  version,
+		Isutcnt:  uint32(len(b.UTLocalIndicators)),
+		Isstdcnt: uint32(len(b.StandardWallIndicators)),
+		Leapcnt:  uint32(len(b.LeapSecondRecords)),
+		Timecnt:  uint32(len(b.TransitionTimes)),
+		Typecnt:  uint32(len(b.LocalTimeTypeRecord)),
+		Charcnt:  uint32(len(b.TimeZoneDesignation)),
+	}
+}
+
+// DecodeOptions controls the behavior of DecodeDataWithOptions.
+// The zero value is the strict behavior of DecodeData.
+type DecodeOptions struct {
+	// LenientFooter makes the decoder accept a footer that lacks the
+	// newline after the TZ string, as found in some malformed files,
+	// if the input ends after a nonempty TZ string.
+	// By default, a missing newline is an error.
+	LenientFooter bool
+}
+
 // DecodeData reads the TZif Data from the given reader.
 // If the version is V1, the V2 fields should be ignored.
 func DecodeData(r io.Reader) (Data, error) {
+	return DecodeDataWithOptions(r, DecodeOptions{})
+}
+
+// DecodeDataWithOptions is like DecodeData but with the given options.
+func DecodeDataWithOptions(r io.Reader, opts DecodeOptions) (Data, error) {
 	var (
 		d   Data
 		err error
@@ -58,7 +220,7 @@ func DecodeData(r io.Reader) (Data, error) {
 		return d, fmt.Errorf("read v1 data block: %w", err)
 	}
 
-	if d.Version > V1 {
+	if d.Version.IsV2Plus() {
 		d.V2Header, err = ReadHeader(r)
 		if err != nil {
 			return d, fmt.Errorf("read v2 header: %w", err)
@@ -67,7 +229,7 @@ func DecodeData(r io.Reader) (Data, error) {
 		if err != nil {
 			return d, fmt.Errorf("read v2 data block: %w", err)
 		}
-		d.V2Footer, err = ReadFooter(r)
+		d.V2Footer, err = readFooter(r, opts.LenientFooter)
 		if err != nil {
 			return d, fmt.Errorf("read footer: %w", err)
 		}
@@ -75,3 +237,56 @@ func DecodeData(r io.Reader) (Data, error) {
 
 	return d, nil
 }
+
+// DecodeV2Only reads the version 2+ TZif data from r, skipping the body of
+// the version 1 data block instead of decoding it. The returned Data has
+// a version 1 header with all counts zero and an empty V1Data, like the
+// result of StripV1, so that it can be encoded again. An error is
+// returned for version 1 files and if r cannot seek, such as when it is a
+// pipe.
+func DecodeV2Only(r io.ReadSeeker) (Data, error) {
+	var (
+		d   Data
+		err error
+	)
+	d.V1Header, err = ReadHeader(r)
+	if err != nil {
+		return d, fmt.Errorf("read v1 header: %w", err)
+	}
+	d.Version = d.V1Header.Version
+	if !d.Version.IsV2Plus() {
+		return d, fmt.Errorf("no v2 data in %v file", d.Version)
+	}
+
+	if _, err := r.Seek(v1BlockSize(d.V1Header), io.SeekCurrent); err != nil {
+		return d, fmt.Errorf("skip v1 data block: %w", err)
+	}
+	d.V1Header = Header{Version: d.Version}
+
+	d.V2Header, err = ReadHeader(r)
+	if err != nil {
+		return d, fmt.Errorf("read v2 header: %w", err)
+	}
+	d.V2Data, err = ReadV2DataBlock(r, d.V2Header)
+	if err != nil {
+		return d, fmt.Errorf("read v2 data block: %w", err)
+	}
+	d.V2Footer, err = ReadFooter(r)
+	if err != nil {
+		return d, fmt.Errorf("read footer: %w", err)
+	}
+	return d, nil
+}
+
+// v1BlockSize returns the size in bytes of the version 1 data block
+// described by the header, with TIME_SIZE being 4.
+func v1BlockSize(h Header) int64 {
+	const timeSize = 4
+	return int64(h.Timecnt)*timeSize +
+		int64(h.Timecnt) +
+		int64(h.Typecnt)*6 +
+		int64(h.Charcnt) +
+		int64(h.Leapcnt)*(timeSize+4) +
+		int64(h.Isstdcnt) +
+		int64(h.Isutcnt)
+}
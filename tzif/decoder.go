@@ -0,0 +1,225 @@
+package tzif
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Decoder decodes TZif data like DecodeData, but reuses its buffers across
+// calls to Decode. This reduces the allocations of programs that decode
+// many files, such as services loading a whole zoneinfo tree.
+//
+// The slices of the Data returned by Decode share memory with the Decoder.
+// They are only valid until the next call to Decode; callers that keep the
+// data longer must copy it. A Decoder must not be used concurrently.
+// The zero value is ready to use.
+type Decoder struct {
+	buf bytes.Buffer
+
+	// Backing arrays of the slices of the last decoded Data.
+	v1       V1DataBlock
+	v2       V2DataBlock
+	tzString []byte
+}
+
+// Decode reads all TZif data from r.
+// If the version is V1, the V2 fields should be ignored.
+func (dec *Decoder) Decode(r io.Reader) (Data, error) {
+	dec.buf.Reset()
+	if _, err := dec.buf.ReadFrom(r); err != nil {
+		return Data{}, err
+	}
+	in := &decodeBuffer{b: dec.buf.Bytes()}
+
+	var d Data
+	d.V1Header = in.header()
+	if in.err != nil {
+		return d, fmt.Errorf("read v1 header: %w", in.err)
+	}
+	d.Version = d.V1Header.Version
+	d.V1Data = dec.v1Block(in, d.V1Header)
+	if in.err != nil {
+		return d, fmt.Errorf("read v1 data block: %w", in.err)
+	}
+	if d.Version == V1 {
+		return d, nil
+	}
+
+	d.V2Header = in.header()
+	if in.err != nil {
+		return d, fmt.Errorf("read v2 header: %w", in.err)
+	}
+	if !d.V2Header.Version.IsV2Plus() {
+		return d, fmt.Errorf("read v2 data block: invalid header version: %v", d.V2Header.Version)
+	}
+	d.V2Data = dec.v2Block(in, d.V2Header)
+	if in.err != nil {
+		return d, fmt.Errorf("read v2 data block: %w", in.err)
+	}
+	d.V2Footer = dec.footer(in)
+	if in.err != nil {
+		return d, fmt.Errorf("read footer: %w", in.err)
+	}
+	return d, nil
+}
+
+func (dec *Decoder) v1Block(in *decodeBuffer, h Header) V1DataBlock {
+	var b V1DataBlock
+	if err := checkCharcnt(h); err != nil && in.err == nil {
+		in.err = err
+		return b
+	}
+	b.TransitionTimes = resize(&dec.v1.TransitionTimes, h.Timecnt)
+	for i := range b.TransitionTimes {
+		b.TransitionTimes[i] = int32(in.uint32())
+	}
+	b.TransitionTypes = resize(&dec.v1.TransitionTypes, h.Timecnt)
+	copy(b.TransitionTypes, in.next(int(h.Timecnt)))
+	b.LocalTimeTypeRecord = resize(&dec.v1.LocalTimeTypeRecord, h.Typecnt)
+	for i := range b.LocalTimeTypeRecord {
+		b.LocalTimeTypeRecord[i] = in.localTimeTypeRecord()
+	}
+	b.TimeZoneDesignation = resize(&dec.v1.TimeZoneDesignation, h.Charcnt)
+	copy(b.TimeZoneDesignation, in.next(int(h.Charcnt)))
+	b.LeapSecondRecords = resize(&dec.v1.LeapSecondRecords, h.Leapcnt)
+	for i := range b.LeapSecondRecords {
+		b.LeapSecondRecords[i] = V1LeapSecondRecord{Occur: int32(in.uint32()), Corr: int32(in.uint32())}
+	}
+	b.StandardWallIndicators = resize(&dec.v1.StandardWallIndicators, h.Isstdcnt)
+	in.bools(b.StandardWallIndicators)
+	b.UTLocalIndicators = resize(&dec.v1.UTLocalIndicators, h.Isutcnt)
+	in.bools(b.UTLocalIndicators)
+	return b
+}
+
+func (dec *Decoder) v2Block(in *decodeBuffer, h Header) V2DataBlock {
+	var b V2DataBlock
+	if err := checkCharcnt(h); err != nil && in.err == nil {
+		in.err = err
+		return b
+	}
+	b.TransitionTimes = resize(&dec.v2.TransitionTimes, h.Timecnt)
+	for i := range b.TransitionTimes {
+		b.TransitionTimes[i] = int64(in.uint64())
+	}
+	b.TransitionTypes = resize(&dec.v2.TransitionTypes, h.Timecnt)
+	copy(b.TransitionTypes, in.next(int(h.Timecnt)))
+	b.LocalTimeTypeRecord = resize(&dec.v2.LocalTimeTypeRecord, h.Typecnt)
+	for i := range b.LocalTimeTypeRecord {
+		b.LocalTimeTypeRecord[i] = in.localTimeTypeRecord()
+	}
+	b.TimeZoneDesignation = resize(&dec.v2.TimeZoneDesignation, h.Charcnt)
+	copy(b.TimeZoneDesignation, in.next(int(h.Charcnt)))
+	b.LeapSecondRecords = resize(&dec.v2.LeapSecondRecords, h.Leapcnt)
+	for i := range b.LeapSecondRecords {
+		b.LeapSecondRecords[i] = V2LeapSecondRecord{Occur: int64(in.uint64()), Corr: int32(in.uint32())}
+	}
+	b.StandardWallIndicators = resize(&dec.v2.StandardWallIndicators, h.Isstdcnt)
+	in.bools(b.StandardWallIndicators)
+	b.UTLocalIndicators = resize(&dec.v2.UTLocalIndicators, h.Isutcnt)
+	in.bools(b.UTLocalIndicators)
+	return b
+}
+
+func (dec *Decoder) footer(in *decodeBuffer) Footer {
+	if nl := in.next(1); in.err == nil && nl[0] != asciiNewLine {
+		in.err = fmt.Errorf("expected newline: %v", nl[0])
+		return Footer{}
+	}
+	i := bytes.IndexByte(in.b, asciiNewLine)
+	if in.err == nil && i < 0 {
+		in.err = fmt.Errorf("reading TZ string: %w", io.ErrUnexpectedEOF)
+	}
+	if in.err != nil {
+		return Footer{}
+	}
+	s := resize(&dec.tzString, uint32(i))
+	copy(s, in.next(i+1))
+	return Footer{TZString: s}
+}
+
+// resize returns a slice of length n backed by *s, growing *s if needed.
+// It returns nil if n is zero, like the Read functions of this package.
+func resize[T any](s *[]T, n uint32) []T {
+	if n == 0 {
+		return nil
+	}
+	if uint32(cap(*s)) < n {
+		*s = make([]T, n)
+	}
+	return (*s)[:n]
+}
+
+// decodeBuffer consumes big-endian values from a byte slice.
+// After the first error, all methods return zero values.
+type decodeBuffer struct {
+	b   []byte
+	err error
+}
+
+func (in *decodeBuffer) next(n int) []byte {
+	if in.err != nil {
+		return nil
+	}
+	if len(in.b) < n {
+		in.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	p := in.b[:n]
+	in.b = in.b[n:]
+	return p
+}
+
+func (in *decodeBuffer) uint32() uint32 {
+	if p := in.next(4); p != nil {
+		return order.Uint32(p)
+	}
+	return 0
+}
+
+func (in *decodeBuffer) uint64() uint64 {
+	if p := in.next(8); p != nil {
+		return order.Uint64(p)
+	}
+	return 0
+}
+
+func (in *decodeBuffer) bools(dst []bool) {
+	for i, c := range in.next(len(dst)) {
+		dst[i] = c != 0
+	}
+}
+
+func (in *decodeBuffer) header() Header {
+	var h Header
+	magic := in.next(len(Magic))
+	if in.err != nil {
+		return h
+	}
+	if !bytes.Equal(magic, Magic[:]) {
+		in.err = fmt.Errorf("invalid magic: %v", magic)
+		return h
+	}
+	if p := in.next(1 + len(h.Reserved)); p != nil {
+		h.Version = Version(p[0])
+		copy(h.Reserved[:], p[1:])
+	}
+	h.Isutcnt = in.uint32()
+	h.Isstdcnt = in.uint32()
+	h.Leapcnt = in.uint32()
+	h.Timecnt = in.uint32()
+	h.Typecnt = in.uint32()
+	h.Charcnt = in.uint32()
+	return h
+}
+
+func (in *decodeBuffer) localTimeTypeRecord() LocalTimeTypeRecord {
+	var r LocalTimeTypeRecord
+	r.Utoff = int32(in.uint32())
+	if p := in.next(2); p != nil {
+		r.Dst = p[0] != 0
+		r.Idx = p[1]
+	}
+	return r
+}
@@ -0,0 +1,117 @@
+package tzif
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecoder_Decode(t *testing.T) {
+	var dec Decoder
+	// Decode in alternating order to exercise the reuse of buffers.
+	for _, name := range []string{"Europe/Zurich", "Pacific/Honolulu", "Europe/Zurich"} {
+		b, err := os.ReadFile("../testdata/zoneinfo/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := DecodeData(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := dec.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("Decode(%s) error: %v", name, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Decode(%s) mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestDecoder_Decode_Truncated(t *testing.T) {
+	b, err := os.ReadFile("../testdata/zoneinfo/Europe/Zurich")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec Decoder
+	for _, n := range []int{0, 10, 100, len(b) - 1} {
+		if _, err := dec.Decode(bytes.NewReader(b[:n])); err == nil {
+			t.Errorf("Decode(%d bytes) = nil error, want error", n)
+		}
+	}
+}
+
+func BenchmarkDecodeData(b *testing.B) {
+	data, err := os.ReadFile("../testdata/zoneinfo/Europe/Zurich")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("DecodeData", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := DecodeData(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Decoder", func(b *testing.B) {
+		b.ReportAllocs()
+		var dec Decoder
+		for i := 0; i < b.N; i++ {
+			if _, err := dec.Decode(bytes.NewReader(data)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestDecodeV2Only(t *testing.T) {
+	for _, name := range []string{"Europe/Zurich", "Pacific/Honolulu"} {
+		b, err := os.ReadFile("../testdata/zoneinfo/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := DecodeData(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = want.StripV1()
+
+		got, err := DecodeV2Only(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("DecodeV2Only(%s) error: %v", name, err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("DecodeV2Only(%s) mismatch (-want +got):\n%s", name, diff)
+		}
+
+		// The result is valid data that round-trips.
+		if err := Validate(got); err != nil {
+			t.Errorf("Validate(DecodeV2Only(%s)) = %v, want nil", name, err)
+		}
+		var buf bytes.Buffer
+		if err := got.Encode(&buf); err != nil {
+			t.Fatalf("Encode(DecodeV2Only(%s)) error: %v", name, err)
+		}
+		decoded, err := DecodeData(&buf)
+		if err != nil {
+			t.Fatalf("DecodeData(Encode(DecodeV2Only(%s))) error: %v", name, err)
+		}
+		if diff := cmp.Diff(got, decoded); diff != "" {
+			t.Errorf("DecodeV2Only(%s) round trip mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestDecodeV2Only_V1(t *testing.T) {
+	var buf bytes.Buffer
+	d := exampleHonolulu()
+	if err := d.EncodeAs(&buf, V1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeV2Only(bytes.NewReader(buf.Bytes())); err == nil {
+		t.Errorf("DecodeV2Only() = nil error, want error for V1 file")
+	}
+}
@@ -0,0 +1,37 @@
+package tzif
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// BuildDesignations packs the given time zone designations into a buffer
+// of NUL-terminated strings, as stored in the time zone designation field
+// of a data block. It returns the buffer, the index into the buffer of
+// each name, suitable for LocalTimeTypeRecord.Idx, and the length of the
+// buffer, suitable for Header.Charcnt.
+//
+// Like zic, names are added in order, and a name is reused if it already
+// occurs in the buffer, either as a whole designation or as the suffix of
+// a longer one. For example, "EST" shares the octets of a preceding
+// "CEST".
+//
+// An error is returned if a name would start beyond index 255, which
+// cannot be represented by LocalTimeTypeRecord.Idx.
+func BuildDesignations(names []string) (buffer []byte, indices []uint8, charcnt uint32, err error) {
+	indices = make([]uint8, len(names))
+	for i, name := range names {
+		term := append([]byte(name), 0)
+		idx := bytes.Index(buffer, term)
+		if idx < 0 {
+			idx = len(buffer)
+			buffer = append(buffer, term...)
+		}
+		if idx > math.MaxUint8 {
+			return nil, nil, 0, fmt.Errorf("designation %q at index %d out of range [0, %d]", name, idx, math.MaxUint8)
+		}
+		indices[i] = uint8(idx)
+	}
+	return buffer, indices, uint32(len(buffer)), nil
+}
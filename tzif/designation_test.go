@@ -0,0 +1,46 @@
+package tzif
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildDesignations(t *testing.T) {
+	names := []string{"LMT", "CEST", "EST", "CET", "ST", "LMT", ""}
+	buffer, indices, charcnt, err := BuildDesignations(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := string(buffer), "LMT\x00CEST\x00CET\x00"; got != want {
+		t.Errorf("buffer = %q, want %q", got, want)
+	}
+	if diff := cmp.Diff([]uint8{0, 4, 5, 9, 6, 0, 3}, indices); diff != "" {
+		t.Errorf("indices mismatch (-want +got):\n%s", diff)
+	}
+	if charcnt != uint32(len(buffer)) {
+		t.Errorf("charcnt = %d, want %d", charcnt, len(buffer))
+	}
+	for i, idx := range indices {
+		if got, _ := designationAt(buffer, idx); got != names[i] {
+			t.Errorf("designation at index %d = %q, want %q", idx, got, names[i])
+		}
+	}
+}
+
+func TestBuildDesignations_Overflow(t *testing.T) {
+	// 64 distinct designations of four octets each, including the NUL,
+	// put the last one at index 252. One more does not fit.
+	var names []string
+	for i := 0; i < 65; i++ {
+		names = append(names, fmt.Sprintf("%03d", i))
+	}
+	if _, _, _, err := BuildDesignations(names[:64]); err != nil {
+		t.Errorf("BuildDesignations(64 names) = %v, want nil", err)
+	}
+	if _, _, _, err := BuildDesignations(names); err == nil {
+		t.Error("BuildDesignations(65 names) = nil error, want error")
+	}
+}
@@ -0,0 +1,68 @@
+package tzif
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+)
+
+// LoadDir decodes all TZif files in the file system, which is laid out like
+// /usr/share/zoneinfo, e.g. os.DirFS("/usr/share/zoneinfo"). It returns the
+// data keyed by the slash-separated path of the files, such as
+// "America/New_York".
+//
+// Files that do not start with the TZif magic, such as zone1970.tab or
+// tzdata.zi, are skipped. Symbolic links to files are loaded like the
+// files, and symbolic links to directories, such as the "posix" and
+// "right" directories of some distributions, are skipped. An error is
+// returned if a TZif file cannot be decoded.
+func LoadDir(fsys fs.FS) (map[string]Data, error) {
+	zones := make(map[string]Data)
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			fi, err := fs.Stat(fsys, path)
+			if err != nil {
+				return err
+			}
+			if !fi.Mode().IsRegular() {
+				return nil
+			}
+		}
+		b, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		if !bytes.HasPrefix(b, Magic[:]) {
+			return nil
+		}
+		data, err := DecodeData(bytes.NewReader(b))
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", path, err)
+		}
+		zones[path] = data
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// VerifyLink returns true if the data of the alias equals the data of its
+// link target in compiled, such as returned by LoadDir. This catches
+// aliases that diverged from their target because they were compiled
+// separately. An error is returned if either name is missing.
+func VerifyLink(compiled map[string]Data, alias, target string) (bool, error) {
+	a, ok := compiled[alias]
+	if !ok {
+		return false, fmt.Errorf("no data for alias %q", alias)
+	}
+	t, ok := compiled[target]
+	if !ok {
+		return false, fmt.Errorf("no data for link target %q", target)
+	}
+	return a.Equal(t), nil
+}
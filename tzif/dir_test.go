@@ -0,0 +1,71 @@
+package tzif
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"Europe/Zurich", "Pacific/Honolulu"} {
+		b, err := os.ReadFile("../testdata/zoneinfo/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "zone1970.tab"), []byte("CH\t+4723+00832\tEurope/Zurich\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Symbolic links to files are loaded, those to directories skipped.
+	if err := os.Symlink("Zurich", filepath.Join(dir, "Europe", "Vaduz")); err != nil {
+		t.Skipf("symbolic links not supported: %v", err)
+	}
+	if err := os.Symlink(".", filepath.Join(dir, "posix")); err != nil {
+		t.Fatal(err)
+	}
+
+	zones, err := LoadDir(os.DirFS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name := range zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if diff := cmp.Diff([]string{"Europe/Vaduz", "Europe/Zurich", "Pacific/Honolulu"}, names); diff != "" {
+		t.Errorf("LoadDir() zones mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(mustDecodeTestData(t, "Europe/Zurich"), zones["Europe/Zurich"]); diff != "" {
+		t.Errorf("LoadDir() Europe/Zurich mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestVerifyLink(t *testing.T) {
+	compiled := map[string]Data{
+		"Europe/Zurich":    mustDecodeTestData(t, "Europe/Zurich"),
+		"Europe/Vaduz":     mustDecodeTestData(t, "Europe/Zurich"),
+		"Pacific/Honolulu": mustDecodeTestData(t, "Pacific/Honolulu"),
+	}
+	if ok, err := VerifyLink(compiled, "Europe/Vaduz", "Europe/Zurich"); !ok || err != nil {
+		t.Errorf("VerifyLink(Europe/Vaduz, Europe/Zurich) = %t, %v, want true, nil", ok, err)
+	}
+	if ok, err := VerifyLink(compiled, "Europe/Vaduz", "Pacific/Honolulu"); ok || err != nil {
+		t.Errorf("VerifyLink(Europe/Vaduz, Pacific/Honolulu) = %t, %v, want false, nil", ok, err)
+	}
+	if _, err := VerifyLink(compiled, "Europe/Busingen", "Europe/Zurich"); err == nil {
+		t.Errorf("VerifyLink(Europe/Busingen, Europe/Zurich) = nil error, want error for missing alias")
+	}
+}
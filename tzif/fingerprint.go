@@ -0,0 +1,73 @@
+package tzif
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// Fingerprint returns a SHA-256 hash of the semantic content of the data:
+// the local time type in effect before the first transition, the
+// transitions with the UT offset, DST flag and designation they switch to,
+// the leap second records and the TZ string of the footer.
+//
+// The fingerprint does not depend on how the content is encoded, such as
+// the order of the local time type records, the layout of the time zone
+// designations, the standard/wall and UT/local indicators, or whether
+// version 1 data is present in a version 2+ file. The version 1 data block
+// is used for version 1 data only.
+func (d Data) Fingerprint() [32]byte {
+	var (
+		times        = d.transitionTimes()
+		types        = d.V2Data.TransitionTypes
+		records      = d.V2Data.LocalTimeTypeRecord
+		designations = d.V2Data.TimeZoneDesignation
+		leaps        = d.V2Data.LeapSecondRecords
+	)
+	if d.Version == V1 {
+		types = d.V1Data.TransitionTypes
+		records = d.V1Data.LocalTimeTypeRecord
+		designations = d.V1Data.TimeZoneDesignation
+		leaps = make([]V2LeapSecondRecord, len(d.V1Data.LeapSecondRecords))
+		for i, r := range d.V1Data.LeapSecondRecords {
+			leaps[i] = V2LeapSecondRecord{Occur: int64(r.Occur), Corr: r.Corr}
+		}
+	}
+
+	var buf []byte
+	putType := func(typ uint8) {
+		var r LocalTimeTypeRecord
+		if int(typ) < len(records) {
+			r = records[typ]
+		}
+		name, _ := designationAt(designations, r.Idx)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(r.Utoff))
+		if r.Dst {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		// Designations cannot contain NUL, so it terminates them unambiguously.
+		buf = append(buf, name...)
+		buf = append(buf, 0)
+	}
+
+	putType(0)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(times)))
+	for i, t := range times {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(t))
+		if i < len(types) {
+			putType(types[i])
+		} else {
+			putType(0)
+		}
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(len(leaps)))
+	for _, r := range leaps {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(r.Occur))
+		buf = binary.BigEndian.AppendUint32(buf, uint32(r.Corr))
+	}
+	if d.Version.IsV2Plus() {
+		buf = append(buf, d.V2Footer.TZString...)
+	}
+	return sha256.Sum256(buf)
+}
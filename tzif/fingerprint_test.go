@@ -0,0 +1,47 @@
+package tzif
+
+import "testing"
+
+func TestData_Fingerprint(t *testing.T) {
+	d := exampleHonolulu()
+
+	// Encode the same content differently: without version 1 data and
+	// indicators, with the local time type records after the first in
+	// reverse order, and with a different layout of the designations.
+	equiv := exampleHonolulu()
+	equiv.V1Header = Header{Version: V2}
+	equiv.V1Data = V1DataBlock{}
+	perm := []uint8{0, 5, 4, 3, 2, 1} // old index to new index
+	newIdx := map[uint8]uint8{0: 16, 4: 12, 8: 8, 12: 4, 16: 0}
+	records := make([]LocalTimeTypeRecord, len(perm))
+	for old, r := range equiv.V2Data.LocalTimeTypeRecord {
+		r.Idx = newIdx[r.Idx]
+		records[perm[old]] = r
+	}
+	equiv.V2Data.LocalTimeTypeRecord = records
+	equiv.V2Data.TimeZoneDesignation = []byte("HPT\x00HWT\x00HDT\x00HST\x00LMT\x00")
+	for i, typ := range equiv.V2Data.TransitionTypes {
+		equiv.V2Data.TransitionTypes[i] = perm[typ]
+	}
+	equiv.V2Data.UTLocalIndicators = nil
+	equiv.V2Data.StandardWallIndicators = nil
+	equiv.V2Header.Isutcnt, equiv.V2Header.Isstdcnt = 0, 0
+	if err := Validate(equiv); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	if d.Fingerprint() != equiv.Fingerprint() {
+		t.Errorf("Fingerprint() differs for semantically equal data")
+	}
+
+	changed := exampleHonolulu()
+	changed.V2Data.LocalTimeTypeRecord[5].Utoff = -36060
+	if d.Fingerprint() == changed.Fingerprint() {
+		t.Errorf("Fingerprint() equal for data with a different offset")
+	}
+	changed = exampleHonolulu()
+	changed.V2Footer.TZString = []byte("HST10HDT")
+	if d.Fingerprint() == changed.Fingerprint() {
+		t.Errorf("Fingerprint() equal for data with a different footer")
+	}
+}
@@ -0,0 +1,430 @@
+package tzif
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// bigBang is the earliest time value that a TZif file SHOULD contain.
+// zic uses it for a transition that marks the start of the data.
+const bigBang = -1 << 59
+
+// Interval is the half-open time interval [Start, End).
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// DSTIntervals returns the periods of daylight saving time that overlap
+// the half-open interval [from, to), in chronological order.
+//
+// The periods are derived from the transitions where the DST flag of the
+// local time type changes. After the last transition, the TZ string in the
+// footer is consulted. Periods that extend beyond the range are clipped to
+// from and to.
+func (d Data) DSTIntervals(from, to time.Time) []Interval {
+	var (
+		intervals []Interval
+		start     time.Time
+		trs       = d.resolvedTransitions(from, to)
+		i         int
+	)
+	initial, _ := d.InitialType()
+	dst := initial.Dst
+	for ; i < len(trs) && !trs[i].Time.After(from); i++ {
+		dst = trs[i].Dst
+	}
+	if dst {
+		start = from
+	}
+	for ; i < len(trs) && trs[i].Time.Before(to); i++ {
+		switch {
+		case trs[i].Dst && !dst:
+			start = trs[i].Time
+		case !trs[i].Dst && dst:
+			intervals = append(intervals, Interval{Start: start, End: trs[i].Time})
+		}
+		dst = trs[i].Dst
+	}
+	if dst && from.Before(to) {
+		intervals = append(intervals, Interval{Start: start, End: to})
+	}
+	return intervals
+}
+
+// ObservesDST returns true if daylight saving time is in effect at any time
+// during the given year, in UT. After the last transition, the TZ string in
+// the footer is consulted.
+func (d Data) ObservesDST(year int) bool {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return len(d.DSTIntervals(from, from.AddDate(1, 0, 0))) > 0
+}
+
+// TransitionIndex returns the index into the transition times of the last
+// transition at or before t, or -1 if t is before all transitions.
+// The version 2+ transition times are used, or the version 1 transition
+// times for version 1 data. The footer is not consulted.
+func (d Data) TransitionIndex(t time.Time) int {
+	times := d.transitionTimes()
+	secs := t.Unix()
+	return sort.Search(len(times), func(i int) bool { return times[i] > secs }) - 1
+}
+
+// OffsetAtEpoch returns the UT offset in effect at the Unix epoch,
+// 1970-01-01T00:00:00Z. Before the first transition, local time type 0
+// is in effect. It returns false if the data has no local time types.
+func (d Data) OffsetAtEpoch() (time.Duration, bool) {
+	return d.offsetAt(time.Unix(0, 0).UTC())
+}
+
+// offsetAt returns the UT offset in effect at the given time.
+// It returns false if the data has no local time types.
+func (d Data) offsetAt(t time.Time) (time.Duration, bool) {
+	lt, ok := d.localTimeAt(t)
+	return lt.Offset, ok
+}
+
+// now returns the current time. It is replaced in tests.
+var now = time.Now
+
+// CurrentOffset returns the UT offset, DST flag and abbreviation of the
+// local time type in effect now. After the last transition, the TZ string
+// in the footer is evaluated. If the data has no local time types, the
+// zero values are returned.
+func (d Data) CurrentOffset() (offset time.Duration, dst bool, abbreviation string) {
+	lt, _ := d.localTimeAt(now())
+	return lt.Offset, lt.Dst, lt.Abbreviation
+}
+
+// OffsetHistogram returns, for each UT offset in effect during the
+// half-open interval [from, to), the total elapsed time it was in effect
+// within the interval. The first and last periods are cut at from and to.
+// It returns nil if the data has no local time types or the interval is
+// empty.
+func (d Data) OffsetHistogram(from, to time.Time) map[time.Duration]time.Duration {
+	if !from.Before(to) {
+		return nil
+	}
+	lt, ok := d.localTimeAt(from)
+	if !ok {
+		return nil
+	}
+	hist := make(map[time.Duration]time.Duration)
+	offset, start := lt.Offset, from
+	for _, tr := range d.Transitions(from, to) {
+		hist[offset] += tr.Time.Sub(start)
+		offset, start = tr.Offset, tr.Time
+	}
+	hist[offset] += to.Sub(start)
+	return hist
+}
+
+// localTimeAt returns the local time type in effect at the given time as
+// the Offset, Dst and Abbreviation of a transition. Before the first
+// transition, local time type 0 is in effect. After the last transition,
+// the TZ string in the footer is consulted.
+// It returns false if the data has no local time types.
+func (d Data) localTimeAt(t time.Time) (ResolvedTransition, bool) {
+	records := d.V2Data.LocalTimeTypeRecord
+	if d.Version == V1 {
+		records = d.V1Data.LocalTimeTypeRecord
+	}
+	if len(records) == 0 {
+		return ResolvedTransition{}, false
+	}
+	initial, name := d.InitialType()
+	lt := ResolvedTransition{Offset: initial.Utoffset(), Dst: initial.Dst, Abbreviation: name}
+	for _, tr := range d.resolvedTransitions(t, t.Add(time.Second)) {
+		if tr.Time.After(t) {
+			break
+		}
+		lt = tr
+	}
+	lt.Time = time.Time{}
+	return lt, true
+}
+
+// TransitionsOnLocalDate returns the transitions whose local date is the
+// given calendar date, in chronological order. The local date of a
+// transition is that of the wall clock just before it, i.e. the instant of
+// the transition plus the UT offset in effect before it. Before the first
+// transition, local time type 0 is in effect. After the last transition,
+// the TZ string in the footer is consulted.
+func (d Data) TransitionsOnLocalDate(year int, month time.Month, day int) []ResolvedTransition {
+	records := d.V2Data.LocalTimeTypeRecord
+	if d.Version == V1 {
+		records = d.V1Data.LocalTimeTypeRecord
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	// A transition falls on the date if its instant plus a UT offset of
+	// the data is on the date, so a margin of the largest offset on each
+	// side covers all of them. Offsets may well exceed a day.
+	var margin time.Duration
+	for _, r := range records {
+		margin = max(margin, r.Utoffset(), -r.Utoffset())
+	}
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	from, to := date.Add(-margin), date.AddDate(0, 0, 1).Add(margin)
+
+	var (
+		trs    []ResolvedTransition
+		offset = records[0].Utoffset()
+	)
+	for _, tr := range d.resolvedTransitions(from, to) {
+		local := tr.Time.Add(offset)
+		if y, m, dd := local.Date(); y == year && m == month && dd == day {
+			trs = append(trs, tr)
+		}
+		offset = tr.Offset
+	}
+	return trs
+}
+
+// Transitions returns the transitions in the half-open interval [from, to)
+// in chronological order. After the last transition, the TZ string in the
+// footer is consulted.
+func (d Data) Transitions(from, to time.Time) []ResolvedTransition {
+	var trs []ResolvedTransition
+	for _, tr := range d.resolvedTransitions(from, to) {
+		if !tr.Time.Before(from) {
+			trs = append(trs, tr)
+		}
+	}
+	return trs
+}
+
+// Timeline returns a human-readable list of the transitions in the
+// half-open interval [from, to), one per line in chronological order.
+// Each line shows the instant of the transition in UTC and as Unix time,
+// followed by the abbreviation, UT offset and DST flag of the new local
+// time type, e.g.
+//
+//	Sun, 31 Mar 2024 01:00:00 UTC (1711846800) => CEST: 2h0m0s (7200), dst
+//
+// After the last transition, the TZ string in the footer is consulted.
+func (d Data) Timeline(from, to time.Time) string {
+	var sb strings.Builder
+	for _, tr := range d.Transitions(from, to) {
+		var dst string
+		if tr.Dst {
+			dst = ", dst"
+		}
+		secs := int64(tr.Offset / time.Second)
+		fmt.Fprintf(&sb, "%s (%d) => %s: %s (%d)%s\n",
+			tr.Time.Format(time.RFC1123), tr.Time.Unix(), tr.Abbreviation, tr.Offset, secs, dst)
+	}
+	return sb.String()
+}
+
+// ValidUntil returns the instant beyond which the data is unreliable and
+// true, or false if the data is valid indefinitely.
+//
+// For version 4 data whose last leap second record denotes the expiration
+// of the leap second table, this is the expiration time. Otherwise, the TZ
+// string in the footer describes local time indefinitely. Without a TZ
+// string, the data is unreliable after the last transition, unless there
+// are no transitions at all and local time type 0 applies at all times.
+func (d Data) ValidUntil() (time.Time, bool) {
+	if exp, ok := d.LeapExpiration(); ok {
+		return exp, true
+	}
+	if d.Version.IsV2Plus() && len(d.V2Footer.TZString) > 0 {
+		return time.Time{}, false
+	}
+	times := d.transitionTimes()
+	if len(times) == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(times[len(times)-1], 0).UTC(), true
+}
+
+// LeapExpiration returns the expiration time of the leap second table and
+// true, or false if there is none. Only version 4 data can denote the
+// expiration, by a last leap second record whose correction equals that of
+// the previous record. Otherwise, the last record is a genuine leap second.
+func (d Data) LeapExpiration() (time.Time, bool) {
+	if !d.Version.SupportsLeapExpiration() {
+		return time.Time{}, false
+	}
+	records := d.V2Data.LeapSecondRecords
+	if n := len(records); n > 1 && records[n-1].Corr == records[n-2].Corr {
+		return time.Unix(records[n-1].Occur, 0).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// ValidFrom returns the time of the earliest meaningful transition and true,
+// or false if there is none. Transitions that only mark the start of the
+// data, at -2**59 or, in version 1 data, at -2**31, are skipped.
+func (d Data) ValidFrom() (time.Time, bool) {
+	for _, t := range d.transitionTimes() {
+		if t <= bigBang || (d.Version == V1 && t == math.MinInt32) {
+			continue
+		}
+		return time.Unix(t, 0).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// MinimalTransitions returns the transitions of the data that cannot be
+// derived from the TZ string in the footer, in chronological order. These
+// are the historical transitions before the longest run of final
+// transitions that the TZ string reproduces with the same instant, UT
+// offset, DST flag and abbreviation. Writers of slim data can omit the
+// others. Without a TZ string, all transitions are returned.
+func (d Data) MinimalTransitions() []ResolvedTransition {
+	times := d.transitionTimes()
+	if len(times) == 0 {
+		return nil
+	}
+	last := time.Unix(times[len(times)-1], 0).UTC()
+	trs := d.resolvedTransitions(last, last.Add(time.Second))
+	if !d.Version.IsV2Plus() || len(d.V2Footer.TZString) == 0 {
+		return trs
+	}
+	z, err := ParseTZString(string(d.V2Footer.TZString))
+	if err != nil {
+		return trs
+	}
+
+	// The year of ExpandTZString is that of the local date, which can
+	// differ from the year of the instant in UT.
+	derivable := func(tr ResolvedTransition) bool {
+		for year := tr.Time.Year() - 1; year <= tr.Time.Year()+1; year++ {
+			for _, ft := range ExpandTZString(z, year) {
+				if sameTransition(ft, tr) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	k := len(trs)
+	for k > 0 && derivable(trs[k-1]) {
+		k--
+	}
+	return trs[:k]
+}
+
+// sameTransition returns true if a and b are transitions at the same
+// instant to the same local time type. Unlike ==, it ignores the location
+// and monotonic clock reading of the times.
+func sameTransition(a, b ResolvedTransition) bool {
+	return a.Time.Equal(b.Time) && a.Offset == b.Offset && a.Dst == b.Dst && a.Abbreviation == b.Abbreviation
+}
+
+// Abbreviations returns the distinct time zone designations of the local
+// time type records, sorted. Designation octets that no record points to,
+// such as padding, are ignored. The version 1 data block is used for
+// version 1 data only.
+func (d Data) Abbreviations() []string {
+	records, designations := d.V2Data.LocalTimeTypeRecord, d.V2Data.TimeZoneDesignation
+	if d.Version == V1 {
+		records, designations = d.V1Data.LocalTimeTypeRecord, d.V1Data.TimeZoneDesignation
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, r := range records {
+		name, ok := designationAt(designations, r.Idx)
+		if ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// transitionTimes returns the transition times of the version 2+ data block,
+// or of the version 1 data block for version 1 data.
+func (d Data) transitionTimes() []int64 {
+	if d.Version.IsV2Plus() {
+		return d.V2Data.TransitionTimes
+	}
+	times := make([]int64, len(d.V1Data.TransitionTimes))
+	for i, t := range d.V1Data.TransitionTimes {
+		times[i] = int64(t)
+	}
+	return times
+}
+
+// InitialType returns local time type 0 and its designation. It is in
+// effect before the first transition, and is typically the LMT record of
+// compiled data. The version 1 data block is used for version 1 data only.
+// The zero values are returned if the data has no local time types.
+func (d Data) InitialType() (LocalTimeTypeRecord, string) {
+	records, designations := d.V2Data.LocalTimeTypeRecord, d.V2Data.TimeZoneDesignation
+	if d.Version == V1 {
+		records, designations = d.V1Data.LocalTimeTypeRecord, d.V1Data.TimeZoneDesignation
+	}
+	if len(records) == 0 {
+		return LocalTimeTypeRecord{}, ""
+	}
+	name, _ := designationAt(designations, records[0].Idx)
+	return records[0], name
+}
+
+// resolvedTransitions returns the transitions of the data before to,
+// followed by the transitions described by the TZ string in the footer
+// after the last transition and before to.
+//
+// Transitions of the footer are generated from the year before from on,
+// which is enough to determine the local time type in effect at from.
+// The version 1 data block is used for version 1 data only.
+func (d Data) resolvedTransitions(from, to time.Time) []ResolvedTransition {
+	var (
+		times        = d.transitionTimes()
+		types        = d.V2Data.TransitionTypes
+		records      = d.V2Data.LocalTimeTypeRecord
+		designations = d.V2Data.TimeZoneDesignation
+	)
+	if d.Version == V1 {
+		types = d.V1Data.TransitionTypes
+		records = d.V1Data.LocalTimeTypeRecord
+		designations = d.V1Data.TimeZoneDesignation
+	}
+
+	var trs []ResolvedTransition
+	for i, t := range times {
+		tt := time.Unix(t, 0).UTC()
+		if !tt.Before(to) || i >= len(types) || int(types[i]) >= len(records) {
+			break
+		}
+		r := records[types[i]]
+		name, _ := designationAt(designations, r.Idx)
+		trs = append(trs, ResolvedTransition{
+			Time:         tt,
+			Offset:       r.Utoffset(),
+			Dst:          r.Dst,
+			Abbreviation: name,
+		})
+	}
+
+	if d.Version == V1 || len(d.V2Footer.TZString) == 0 {
+		return trs
+	}
+	z, err := ParseTZString(string(d.V2Footer.TZString))
+	if err != nil {
+		return trs
+	}
+	firstYear := from.Year() - 1
+	var last time.Time
+	if n := len(times); n > 0 {
+		last = time.Unix(times[n-1], 0).UTC()
+		firstYear = max(firstYear, last.Year())
+	}
+	for year := firstYear; year <= to.Year(); year++ {
+		for _, t := range ExpandTZString(z, year) {
+			if (len(times) > 0 && !t.Time.After(last)) || !t.Time.Before(to) {
+				continue
+			}
+			trs = append(trs, t)
+		}
+	}
+	return trs
+}
@@ -0,0 +1,440 @@
+package tzif
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func mustDecodeTestData(t *testing.T, name string) Data {
+	t.Helper()
+	b, err := os.ReadFile("../testdata/zoneinfo/" + name)
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+	d, err := DecodeData(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to decode testdata: %v", err)
+	}
+	return d
+}
+
+func TestData_DSTIntervals(t *testing.T) {
+	d := mustDecodeTestData(t, "Europe/Zurich")
+	utc := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []Interval
+	}{
+		{
+			name: "transitions",
+			from: utc(2023, time.January, 1, 0),
+			to:   utc(2025, time.January, 1, 0),
+			want: []Interval{
+				{Start: utc(2023, time.March, 26, 1), End: utc(2023, time.October, 29, 1)},
+				{Start: utc(2024, time.March, 31, 1), End: utc(2024, time.October, 27, 1)},
+			},
+		},
+		{
+			name: "clipped",
+			from: utc(2024, time.July, 1, 0),
+			to:   utc(2025, time.April, 1, 0),
+			want: []Interval{
+				{Start: utc(2024, time.July, 1, 0), End: utc(2024, time.October, 27, 1)},
+				{Start: utc(2025, time.March, 30, 1), End: utc(2025, time.April, 1, 0)},
+			},
+		},
+		{
+			name: "footer",
+			from: utc(2040, time.January, 1, 0),
+			to:   utc(2042, time.January, 1, 0),
+			want: []Interval{
+				{Start: utc(2040, time.March, 25, 1), End: utc(2040, time.October, 28, 1)},
+				{Start: utc(2041, time.March, 31, 1), End: utc(2041, time.October, 27, 1)},
+			},
+		},
+		{
+			name: "no DST",
+			from: utc(1900, time.January, 1, 0),
+			to:   utc(1940, time.January, 1, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := d.DSTIntervals(tt.from, tt.to)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("DSTIntervals() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestData_OffsetAtEpoch(t *testing.T) {
+	tests := map[string]time.Duration{
+		"Pacific/Honolulu": -10 * time.Hour,
+		"Europe/Zurich":    time.Hour,
+	}
+	for name, want := range tests {
+		d := mustDecodeTestData(t, name)
+		got, ok := d.OffsetAtEpoch()
+		if !ok || got != want {
+			t.Errorf("%s: OffsetAtEpoch() = %v, %t, want %v, true", name, got, ok, want)
+		}
+	}
+
+	if _, ok := (Data{Version: V2}).OffsetAtEpoch(); ok {
+		t.Errorf("OffsetAtEpoch() of empty data = true, want false")
+	}
+}
+
+func TestData_Timeline(t *testing.T) {
+	d := mustDecodeTestData(t, "Europe/Zurich")
+	got := d.Timeline(time.Date(1941, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(1943, time.January, 1, 0, 0, 0, 0, time.UTC))
+	want := `Mon, 05 May 1941 00:00:00 UTC (-904435200) => CEST: 2h0m0s (7200), dst
+Mon, 06 Oct 1941 00:00:00 UTC (-891129600) => CET: 1h0m0s (3600)
+Mon, 04 May 1942 00:00:00 UTC (-872985600) => CEST: 2h0m0s (7200), dst
+Mon, 05 Oct 1942 00:00:00 UTC (-859680000) => CET: 1h0m0s (3600)
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Timeline() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestData_Transitions(t *testing.T) {
+	d := mustDecodeTestData(t, "Europe/Zurich")
+	got := d.Transitions(time.Unix(-891129600, 0), time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC))
+	if len(got) == 0 {
+		t.Fatal("Transitions() = none, want some")
+	}
+	// The interval is half-open and extends past the last transition
+	// into the range of the TZ string.
+	if want := time.Unix(-891129600, 0).UTC(); !got[0].Time.Equal(want) {
+		t.Errorf("first transition at %v, want %v", got[0].Time, want)
+	}
+	want := ResolvedTransition{Time: time.Date(2024, time.October, 27, 1, 0, 0, 0, time.UTC), Offset: time.Hour, Abbreviation: "CET"}
+	if diff := cmp.Diff(want, got[len(got)-1]); diff != "" {
+		t.Errorf("last transition mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestData_OffsetHistogram(t *testing.T) {
+	d := mustDecodeTestData(t, "Europe/Zurich")
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+	got := d.OffsetHistogram(from, to)
+
+	// CEST from 2024-03-31 01:00 UTC to 2024-10-27 01:00 UTC.
+	dst := 210 * 24 * time.Hour
+	want := map[time.Duration]time.Duration{
+		time.Hour:     to.Sub(from) - dst,
+		2 * time.Hour: dst,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("OffsetHistogram() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Both offsets account for roughly half of the year.
+	for offset, dur := range got {
+		if share := float64(dur) / float64(to.Sub(from)); share < 0.4 || share > 0.6 {
+			t.Errorf("offset %v in effect for %.2f of the year, want about half", offset, share)
+		}
+	}
+
+	// The interval may start and end within a period.
+	from, to = time.Date(2024, time.October, 26, 0, 0, 0, 0, time.UTC), time.Date(2024, time.October, 28, 0, 0, 0, 0, time.UTC)
+	want = map[time.Duration]time.Duration{
+		time.Hour:     23 * time.Hour,
+		2 * time.Hour: 25 * time.Hour,
+	}
+	if diff := cmp.Diff(want, d.OffsetHistogram(from, to)); diff != "" {
+		t.Errorf("OffsetHistogram(partial) mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := d.OffsetHistogram(to, from); got != nil {
+		t.Errorf("OffsetHistogram(empty interval) = %v, want nil", got)
+	}
+}
+
+func TestData_ValidUntil(t *testing.T) {
+	// Version 4 data whose leap second table expires on 2025-06-28.
+	v4 := exampleHonolulu()
+	v4.Version, v4.V1Header.Version, v4.V2Header.Version = V4, V4, V4
+	v4.V2Header.Leapcnt = 2
+	v4.V2Data.LeapSecondRecords = []V2LeapSecondRecord{
+		{Occur: 1483228826, Corr: 27},
+		{Occur: 1751068827, Corr: 27},
+	}
+	noFooter := exampleHonolulu()
+	noFooter.V2Footer = Footer{}
+
+	tests := []struct {
+		name   string
+		d      Data
+		want   time.Time
+		wantOK bool
+	}{
+		{"V4 expiration", v4, time.Unix(1751068827, 0).UTC(), true},
+		{"V2 footer", exampleHonolulu(), time.Time{}, false},
+		{"V2 without footer", noFooter, time.Unix(-712150200, 0).UTC(), true},
+	}
+	for _, tt := range tests {
+		got, ok := tt.d.ValidUntil()
+		if !got.Equal(tt.want) || ok != tt.wantOK {
+			t.Errorf("%s: ValidUntil() = %v, %t, want %v, %t", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestData_LeapExpiration(t *testing.T) {
+	records := []V2LeapSecondRecord{
+		{Occur: 1435708825, Corr: 26},
+		{Occur: 1483228826, Corr: 27},
+		{Occur: 1751068827, Corr: 27},
+	}
+	v4 := exampleHonolulu()
+	v4.Version, v4.V1Header.Version, v4.V2Header.Version = V4, V4, V4
+	v4.V2Header.Leapcnt = uint32(len(records))
+	v4.V2Data.LeapSecondRecords = records
+
+	genuine := v4
+	genuine.V2Header.Leapcnt--
+	genuine.V2Data.LeapSecondRecords = records[:2]
+
+	v3 := v4
+	v3.Version, v3.V1Header.Version, v3.V2Header.Version = V3, V3, V3
+
+	tests := []struct {
+		name   string
+		d      Data
+		want   time.Time
+		wantOK bool
+	}{
+		{"V4 expiration", v4, time.Unix(1751068827, 0).UTC(), true},
+		{"V4 genuine leap second", genuine, time.Time{}, false},
+		{"V3", v3, time.Time{}, false},
+		{"no leap seconds", exampleHonolulu(), time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.d.LeapExpiration()
+		if !got.Equal(tt.want) || ok != tt.wantOK {
+			t.Errorf("%s: LeapExpiration() = %v, %t, want %v, %t", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestData_ValidFrom(t *testing.T) {
+	d := exampleHonolulu()
+	if got, ok := d.ValidFrom(); !ok || !got.Equal(time.Unix(-2334101314, 0)) {
+		t.Errorf("ValidFrom() = %v, %t, want %v, true", got, ok, time.Unix(-2334101314, 0).UTC())
+	}
+
+	// The version 1 data starts with a transition at -2**31.
+	d.Version = V1
+	if got, ok := d.ValidFrom(); !ok || !got.Equal(time.Unix(-1157283000, 0)) {
+		t.Errorf("V1 ValidFrom() = %v, %t, want %v, true", got, ok, time.Unix(-1157283000, 0).UTC())
+	}
+
+	if _, ok := exampleJerusalem().ValidFrom(); !ok {
+		t.Errorf("ValidFrom() = false, want true")
+	}
+	if _, ok := (Data{Version: V2}).ValidFrom(); ok {
+		t.Errorf("ValidFrom() of empty data = true, want false")
+	}
+}
+
+func TestData_ObservesDST(t *testing.T) {
+	tests := []struct {
+		name string
+		year int
+		want bool
+	}{
+		{"Europe/Zurich", 1941, true},
+		{"Europe/Zurich", 1950, false},
+		{"Europe/Zurich", 2024, true},
+		{"Europe/Zurich", 2100, true}, // from the footer
+		// Hawaii observed daylight saving time in 1933 and war time
+		// during World War II, but not since.
+		{"Pacific/Honolulu", 1933, true},
+		{"Pacific/Honolulu", 1944, true},
+		{"Pacific/Honolulu", 1950, false},
+		{"Pacific/Honolulu", 2024, false},
+	}
+	for _, tt := range tests {
+		d := mustDecodeTestData(t, tt.name)
+		if got := d.ObservesDST(tt.year); got != tt.want {
+			t.Errorf("%s: ObservesDST(%d) = %t, want %t", tt.name, tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestData_TransitionsOnLocalDate(t *testing.T) {
+	d := mustDecodeTestData(t, "Europe/Zurich")
+	tests := []struct {
+		year  int
+		month time.Month
+		day   int
+		want  []ResolvedTransition
+	}{
+		{
+			// Clocks spring forward from 02:00 CET to 03:00 CEST.
+			year: 2024, month: time.March, day: 31,
+			want: []ResolvedTransition{{Time: time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC), Offset: 2 * time.Hour, Dst: true, Abbreviation: "CEST"}},
+		},
+		{year: 2024, month: time.March, day: 30},
+		{year: 2024, month: time.April, day: 1},
+		{
+			// Clocks fall back from 03:00 CEST to 02:00 CET.
+			year: 2024, month: time.October, day: 27,
+			want: []ResolvedTransition{{Time: time.Date(2024, time.October, 27, 1, 0, 0, 0, time.UTC), Offset: time.Hour, Abbreviation: "CET"}},
+		},
+		{
+			// From the footer.
+			year: 2100, month: time.March, day: 28,
+			want: []ResolvedTransition{{Time: time.Date(2100, time.March, 28, 1, 0, 0, 0, time.UTC), Offset: 2 * time.Hour, Dst: true, Abbreviation: "CEST"}},
+		},
+	}
+	for _, tt := range tests {
+		got := d.TransitionsOnLocalDate(tt.year, tt.month, tt.day)
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("TransitionsOnLocalDate(%d, %v, %d) mismatch (-want +got):\n%s", tt.year, tt.month, tt.day, diff)
+		}
+	}
+}
+
+func TestData_TransitionsOnLocalDate_LargeOffset(t *testing.T) {
+	// At 23:30 local time on March 29, 2020, it is already March 31 in UT.
+	d, err := DataFromTZString("<-2459>24:59<-2359>,M3.5.0/23:30,M10.5.0", 2020, 2020)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := d.TransitionsOnLocalDate(2020, time.March, 29)
+	want := []ResolvedTransition{{Time: time.Date(2020, time.March, 31, 0, 29, 0, 0, time.UTC), Offset: -(23*time.Hour + 59*time.Minute), Dst: true, Abbreviation: "-2359"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("TransitionsOnLocalDate(2020, March, 29) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestData_TransitionIndex(t *testing.T) {
+	d := exampleHonolulu()
+	tests := []struct {
+		unix int64
+		want int
+	}{
+		{-2334101315, -1}, // before the first transition
+		{-2334101314, 0},  // at the first transition
+		{-2000000000, 0},
+		{-1157283001, 0},
+		{-1157283000, 1},
+		{-800000000, 3},
+		{-712150201, 5},
+		{-712150200, 6}, // at the last transition
+		{0, 6},
+	}
+	for _, tt := range tests {
+		if got := d.TransitionIndex(time.Unix(tt.unix, 0)); got != tt.want {
+			t.Errorf("TransitionIndex(%d) = %d, want %d", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestData_Abbreviations(t *testing.T) {
+	want := []string{"HDT", "HPT", "HST", "HWT", "LMT"}
+	if diff := cmp.Diff(want, exampleHonolulu().Abbreviations()); diff != "" {
+		t.Errorf("Abbreviations() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestData_CurrentOffset(t *testing.T) {
+	defer func(orig func() time.Time) { now = orig }(now)
+
+	d := mustDecodeTestData(t, "Europe/Zurich")
+	tests := []struct {
+		now    time.Time
+		offset time.Duration
+		dst    bool
+		abbr   string
+	}{
+		// Beyond the last transition of the fixture, from the footer.
+		{time.Date(2050, time.July, 1, 12, 0, 0, 0, time.UTC), 2 * time.Hour, true, "CEST"},
+		{time.Date(2050, time.December, 1, 12, 0, 0, 0, time.UTC), time.Hour, false, "CET"},
+		// From the transitions.
+		{time.Date(1941, time.July, 1, 12, 0, 0, 0, time.UTC), 2 * time.Hour, true, "CEST"},
+		// Before the first transition.
+		{time.Date(1800, time.January, 1, 0, 0, 0, 0, time.UTC), 34*time.Minute + 8*time.Second, false, "LMT"},
+	}
+	for _, tt := range tests {
+		now = func() time.Time { return tt.now }
+		offset, dst, abbr := d.CurrentOffset()
+		if offset != tt.offset || dst != tt.dst || abbr != tt.abbr {
+			t.Errorf("CurrentOffset() at %v = %v, %t, %q, want %v, %t, %q", tt.now, offset, dst, abbr, tt.offset, tt.dst, tt.abbr)
+		}
+	}
+}
+
+func TestData_MinimalTransitions(t *testing.T) {
+	// The EU rules of the footer apply since 1996; in 1995, DST still
+	// ended in September.
+	zurich := mustDecodeTestData(t, "Europe/Zurich")
+	got := zurich.MinimalTransitions()
+	if len(got) == 0 {
+		t.Fatal("MinimalTransitions() = none, want historical transitions")
+	}
+	want := ResolvedTransition{Time: time.Date(1995, time.September, 24, 1, 0, 0, 0, time.UTC), Offset: time.Hour, Abbreviation: "CET"}
+	if diff := cmp.Diff(want, got[len(got)-1]); diff != "" {
+		t.Errorf("last minimal transition mismatch (-want +got):\n%s", diff)
+	}
+	if n := len(zurich.V2Data.TransitionTimes); len(got) >= n {
+		t.Errorf("len(MinimalTransitions()) = %d, want less than %d", len(got), n)
+	}
+
+	// The footer of Honolulu has no DST, so it reproduces no transitions.
+	honolulu := exampleHonolulu()
+	if got := honolulu.MinimalTransitions(); len(got) != len(honolulu.V2Data.TransitionTimes) {
+		t.Errorf("len(MinimalTransitions()) = %d, want all %d transitions", len(got), len(honolulu.V2Data.TransitionTimes))
+	}
+}
+
+func TestData_MinimalTransitions_NewYear(t *testing.T) {
+	// DST ends at 00:00 on January 1 local time, which is still December 31
+	// in UT, so the rule year differs from the year of the instant.
+	d, err := DataFromTZString("<+13>-13<+14>,M11.1.0,J1/0", 2020, 2022)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(d.V2Data.TransitionTimes); n == 0 {
+		t.Fatal("DataFromTZString() has no transitions")
+	}
+	if got := d.MinimalTransitions(); len(got) != 0 {
+		t.Errorf("MinimalTransitions() = %v, want none, as the TZ string derives all", got)
+	}
+}
+
+func TestSameTransition(t *testing.T) {
+	utc := ResolvedTransition{Time: time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC), Offset: 2 * time.Hour, Dst: true, Abbreviation: "CEST"}
+	zurich := utc
+	zurich.Time = utc.Time.In(time.FixedZone("CEST", 7200))
+	if !sameTransition(utc, zurich) {
+		t.Errorf("sameTransition(%v, %v) = false, want true for the same instant", utc.Time, zurich.Time)
+	}
+	other := utc
+	other.Abbreviation = "MESZ"
+	if sameTransition(utc, other) {
+		t.Error("sameTransition() = true for different abbreviations, want false")
+	}
+}
+
+func TestData_InitialType(t *testing.T) {
+	r, name := exampleHonolulu().InitialType()
+	if want := (LocalTimeTypeRecord{Utoff: -37886, Dst: false, Idx: 0}); r != want || name != "LMT" {
+		t.Errorf("InitialType() = %+v, %q, want %+v, %q", r, name, want, "LMT")
+	}
+	if r, name := (Data{Version: V2}).InitialType(); r != (LocalTimeTypeRecord{}) || name != "" {
+		t.Errorf("InitialType() of empty data = %+v, %q, want zero values", r, name)
+	}
+}
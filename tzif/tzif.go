@@ -16,10 +16,12 @@
 package tzif
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"time"
 )
 
 // NOTE: All multi-octet integer values MUST be stored in network octet
@@ -49,6 +51,19 @@ func (v Version) String() string {
 	}
 }
 
+// IsV2Plus returns true if the version is 2 or later, that is, if a file of
+// the version has a version 2+ header, data block and footer.
+func (v Version) IsV2Plus() bool {
+	return v >= V2
+}
+
+// SupportsLeapExpiration returns true if the version is 4 or later, that is,
+// if the leap second records of a file of the version may denote
+// truncation and expiration of the leap second table.
+func (v Version) SupportsLeapExpiration() bool {
+	return v >= V4
+}
+
 const (
 	// V1 represents a version 1 TZif file.
 	//
@@ -152,7 +167,10 @@ type Header struct {
 }
 
 // Write writes the Header to w.
+// The reserved octets are always written as zero, like zic does,
+// regardless of the contents of the Reserved field.
 func (h Header) Write(w io.Writer) error {
+	h.Reserved = [15]byte{}
 	if _, err := w.Write(Magic[:]); err != nil {
 		return err
 	}
@@ -288,8 +306,22 @@ func (b V1DataBlock) Write(w io.Writer) error {
 	return nil
 }
 
+// checkCharcnt returns an error if the header declares local time type
+// records but no time zone designations for them to point into.
+// This is checked before reading a data block, so that such data is
+// rejected early rather than resolved to designations that do not exist.
+func checkCharcnt(h Header) error {
+	if h.Typecnt > 0 && h.Charcnt == 0 {
+		return fmt.Errorf("charcnt must not be zero with typecnt %d", h.Typecnt)
+	}
+	return nil
+}
+
 func ReadV1DataBlock(r io.Reader, h Header) (V1DataBlock, error) {
 	var b V1DataBlock
+	if err := checkCharcnt(h); err != nil {
+		return b, err
+	}
 	if h.Timecnt > 0 {
 		b.TransitionTimes = make([]int32, h.Timecnt)
 		if err := binary.Read(r, order, &b.TransitionTimes); err != nil {
@@ -498,11 +530,14 @@ func (b V2DataBlock) Write(w io.Writer) error {
 }
 
 func ReadV2DataBlock(r io.Reader, h Header) (V2DataBlock, error) {
-	if h.Version < V2 {
+	if !h.Version.IsV2Plus() {
 		return V2DataBlock{}, fmt.Errorf("invalid header version: %v", h.Version)
 	}
 
 	var b V2DataBlock
+	if err := checkCharcnt(h); err != nil {
+		return b, err
+	}
 	if h.Timecnt > 0 {
 		b.TransitionTimes = make([]int64, h.Timecnt)
 		if err := binary.Read(r, order, &b.TransitionTimes); err != nil {
@@ -590,6 +625,13 @@ func (r V2LeapSecondRecord) Write(w io.Writer) error {
 	return binary.Write(w, order, r.Corr)
 }
 
+// The range of UT offsets that local time type records SHOULD have,
+// in seconds.
+const (
+	minUtoff = -89999
+	maxUtoff = 93599
+)
+
 // LocalTimeTypeRecord represents a local time type record.
 // Each record has the following format (the lengths of multi-octet fields
 // are shown in parentheses):
@@ -626,6 +668,25 @@ type LocalTimeTypeRecord struct {
 	Idx uint8
 }
 
+// NewLocalTimeType returns a local time type record with the given UT
+// offset, DST flag and designation index. It returns an error if the
+// offset is not a whole number of seconds or outside the range
+// [-89999, 93599] seconds that the RFC recommends.
+func NewLocalTimeType(offset time.Duration, dst bool, idx uint8) (LocalTimeTypeRecord, error) {
+	if offset%time.Second != 0 {
+		return LocalTimeTypeRecord{}, fmt.Errorf("offset %v is not a whole number of seconds", offset)
+	}
+	if secs := offset / time.Second; secs < minUtoff || secs > maxUtoff {
+		return LocalTimeTypeRecord{}, fmt.Errorf("offset %v out of range [%d, %d] seconds", offset, minUtoff, maxUtoff)
+	}
+	return LocalTimeTypeRecord{Utoff: int32(offset / time.Second), Dst: dst, Idx: idx}, nil
+}
+
+// Utoffset returns the UT offset of the local time type as a duration.
+func (r LocalTimeTypeRecord) Utoffset() time.Duration {
+	return time.Duration(r.Utoff) * time.Second
+}
+
 func (r LocalTimeTypeRecord) Write(w io.Writer) error {
 	if err := binary.Write(w, order, r.Utoff); err != nil {
 		return err
@@ -674,7 +735,21 @@ func (f Footer) Write(w io.Writer) error {
 	return err
 }
 
+// ReadFooter reads the footer from r.
+// If r is a *bufio.Reader, the footer is read in two buffered reads up to
+// the delimiting newlines. Otherwise, it is read one byte at a time so that
+// nothing after the footer is consumed.
 func ReadFooter(r io.Reader) (Footer, error) {
+	return readFooter(r, false)
+}
+
+// readFooter reads the footer from r. If lenient is true, the end of the
+// input after a nonempty TZ string is accepted in place of the trailing
+// newline.
+func readFooter(r io.Reader, lenient bool) (Footer, error) {
+	if br, ok := r.(*bufio.Reader); ok {
+		return readFooterBuffered(br, lenient)
+	}
 	var f Footer
 	buf := make([]byte, 1)
 	if _, err := r.Read(buf); err != nil {
@@ -686,6 +761,9 @@ func ReadFooter(r io.Reader) (Footer, error) {
 	var b []byte
 	for {
 		if _, err := r.Read(buf); err != nil {
+			if lenient && err == io.EOF && len(b) > 0 {
+				break
+			}
 			return f, fmt.Errorf("reading TZ string: %w", err)
 		}
 		if buf[0] == asciiNewLine {
@@ -696,3 +774,25 @@ func ReadFooter(r io.Reader) (Footer, error) {
 	f.TZString = b
 	return f, nil
 }
+
+// readFooterBuffered reads the footer from a *bufio.Reader.
+func readFooterBuffered(r *bufio.Reader, lenient bool) (Footer, error) {
+	var f Footer
+	nl, err := r.ReadString(asciiNewLine)
+	if err != nil {
+		return f, fmt.Errorf("reading newline: %w", err)
+	}
+	if nl != "\n" {
+		return f, fmt.Errorf("expected newline: %v", nl[0])
+	}
+	s, err := r.ReadString(asciiNewLine)
+	switch {
+	case lenient && err == io.EOF && len(s) > 0:
+		f.TZString = []byte(s)
+	case err != nil:
+		return f, fmt.Errorf("reading TZ string: %w", err)
+	case len(s) > 1:
+		f.TZString = []byte(s[:len(s)-1])
+	}
+	return f, nil
+}
@@ -1,9 +1,12 @@
 package tzif
 
 import (
+	"bufio"
 	"bytes"
+	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -44,6 +47,21 @@ func TestHeader_Write(t *testing.T) {
 	}
 }
 
+func TestHeader_Write_ZeroesReserved(t *testing.T) {
+	var buf bytes.Buffer
+	header := Header{Version: V2, Typecnt: 1, Charcnt: 1}
+	for i := range header.Reserved {
+		header.Reserved[i] = byte(i + 1)
+	}
+	if err := header.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.Bytes()[len(Magic)+1 : len(Magic)+1+len(header.Reserved)]
+	if diff := cmp.Diff(make([]byte, len(header.Reserved)), got); diff != "" {
+		t.Errorf("reserved bytes mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestV1FileRepresentingUTCWithLeapSeconds(t *testing.T) {
 	// This is the example B.1. from RFC 8536.
 	header := Header{
@@ -754,6 +772,59 @@ func TestReadFooter(t *testing.T) {
 	}
 }
 
+func TestReadFooter_Buffered(t *testing.T) {
+	for _, tz := range []string{"", "CET-1CEST,M3.5.0,M10.5.0/3"} {
+		f := Footer{TZString: []byte(tz)}
+		if tz == "" {
+			f.TZString = nil
+		}
+		var buf bytes.Buffer
+		if err := f.Write(&buf); err != nil {
+			t.Fatalf("write footer: %v", err)
+		}
+		got, err := ReadFooter(bufio.NewReader(&buf))
+		if err != nil {
+			t.Fatalf("read footer %q: %v", tz, err)
+		}
+		if diff := cmp.Diff(got, f); diff != "" {
+			t.Errorf("ReadFooter(%q) mismatch (-got +want):\n%s", tz, diff)
+		}
+	}
+
+	if _, err := ReadFooter(bufio.NewReader(strings.NewReader("TZ\n"))); err == nil {
+		t.Errorf("ReadFooter() = nil error, want error for missing leading newline")
+	}
+	if _, err := ReadFooter(bufio.NewReader(strings.NewReader("\nTZ"))); err == nil {
+		t.Errorf("ReadFooter() = nil error, want error for missing trailing newline")
+	}
+}
+
+func BenchmarkReadFooter(b *testing.B) {
+	var buf bytes.Buffer
+	if err := (Footer{TZString: []byte("CET-1CEST,M3.5.0,M10.5.0/3")}).Write(&buf); err != nil {
+		b.Fatal(err)
+	}
+	footer := buf.Bytes()
+	b.Run("Reader", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ReadFooter(bytes.NewReader(footer)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("bufio.Reader", func(b *testing.B) {
+		b.ReportAllocs()
+		br := bufio.NewReader(nil)
+		for i := 0; i < b.N; i++ {
+			br.Reset(bytes.NewReader(footer))
+			if _, err := ReadFooter(br); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
 func TestData_Encode_V1(t *testing.T) {
 	v1h := Header{
 		Version:  V1,
@@ -819,6 +890,35 @@ func TestData_Encode_V1(t *testing.T) {
 	}
 }
 
+func TestData_Encode_V1Only_RoundTrip(t *testing.T) {
+	// A version 1 file with transitions and no version 2 header, data
+	// block or footer.
+	honolulu := exampleHonolulu()
+	h := honolulu.V1Header
+	h.Version = V1
+	want := Data{Version: V1, V1Header: h, V1Data: honolulu.V1Data}
+	if err := Validate(want); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := want.Encode(&buf); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	headerSize := int64(len(Magic) + 1 + len(h.Reserved) + 6*4)
+	if got, want := int64(buf.Len()), headerSize+v1BlockSize(h); got != want {
+		t.Errorf("encoded %d bytes, want %d (v1 header and data block only)", got, want)
+	}
+
+	got, err := DecodeData(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("decode mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestData_Encode_V2(t *testing.T) {
 	v1h := Header{
 		Version:  V2,
@@ -938,3 +1038,203 @@ func TestData_Encode_V2(t *testing.T) {
 		t.Errorf("decode mismatch (-got +want):\n%s", diff)
 	}
 }
+
+func TestData_EncodeAs(t *testing.T) {
+	f := exampleJerusalem()
+	f.V2Footer = Footer{TZString: []byte("IST-2IDT,M3.4.4/2,M10.5.0")}
+
+	var buf bytes.Buffer
+	if err := f.EncodeAs(&buf, V2); err != nil {
+		t.Fatalf("EncodeAs(V2) failed: %v", err)
+	}
+	got, err := DecodeData(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	want := f
+	want.Version = V2
+	want.V1Header.Version = V2
+	want.V2Header.Version = V2
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("decode mismatch (-got +want):\n%s", diff)
+	}
+	if err := Validate(got); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestData_EncodeAs_Incompatible(t *testing.T) {
+	// The original footer of example B.3 uses a transition time of 26 hours.
+	f := exampleJerusalem()
+	if err := f.EncodeAs(io.Discard, V2); err == nil {
+		t.Errorf("EncodeAs(V2) = nil, want error for TZ string extension")
+	}
+	if err := f.EncodeAs(io.Discard, V3); err != nil {
+		t.Errorf("EncodeAs(V3) = %v, want nil", err)
+	}
+	// Example B.3 has no version 1 data.
+	if err := f.EncodeAs(io.Discard, V1); err == nil {
+		t.Errorf("EncodeAs(V1) = nil, want error for missing version 1 data")
+	}
+
+	f.V2Footer.TZString = []byte("<ISRAELSTDT>-2")
+	if err := f.EncodeAs(io.Discard, V2); err == nil {
+		t.Errorf("EncodeAs(V2) = nil, want error for long abbreviation")
+	}
+	if err := f.EncodeAs(io.Discard, V3); err != nil {
+		t.Errorf("EncodeAs(V3) = %v, want nil", err)
+	}
+
+	// Version 1 data has no version 2+ data block to write.
+	v1 := exampleHonolulu()
+	v1.Version, v1.V1Header.Version = V1, V1
+	v1.V2Header, v1.V2Data, v1.V2Footer = Header{}, V2DataBlock{}, Footer{}
+	for _, v := range []Version{V2, V3, V4} {
+		if err := v1.EncodeAs(io.Discard, v); err == nil {
+			t.Errorf("EncodeAs(%v) = nil, want error for missing version 2+ data", v)
+		}
+	}
+	if err := v1.EncodeAs(io.Discard, V1); err != nil {
+		t.Errorf("EncodeAs(V1) = %v, want nil", err)
+	}
+}
+
+func TestVersion_Features(t *testing.T) {
+	tests := []struct {
+		v                          Version
+		wantV2Plus, wantLeapExpiry bool
+	}{
+		{V1, false, false},
+		{V2, true, false},
+		{V3, true, false},
+		{V4, true, true},
+	}
+	for _, tt := range tests {
+		if got := tt.v.IsV2Plus(); got != tt.wantV2Plus {
+			t.Errorf("%v.IsV2Plus() = %t, want %t", tt.v, got, tt.wantV2Plus)
+		}
+		if got := tt.v.SupportsLeapExpiration(); got != tt.wantLeapExpiry {
+			t.Errorf("%v.SupportsLeapExpiration() = %t, want %t", tt.v, got, tt.wantLeapExpiry)
+		}
+	}
+}
+
+func TestNewLocalTimeType(t *testing.T) {
+	tests := []struct {
+		offset  time.Duration
+		want    LocalTimeTypeRecord
+		wantErr bool
+	}{
+		{offset: -10 * time.Hour, want: LocalTimeTypeRecord{Utoff: -36000, Dst: true, Idx: 4}},
+		{offset: 34*time.Minute + 8*time.Second, want: LocalTimeTypeRecord{Utoff: 2048, Dst: true, Idx: 4}},
+		{offset: -89999 * time.Second, want: LocalTimeTypeRecord{Utoff: -89999, Dst: true, Idx: 4}},
+		{offset: 93599 * time.Second, want: LocalTimeTypeRecord{Utoff: 93599, Dst: true, Idx: 4}},
+		{offset: -25 * time.Hour, wantErr: true},
+		{offset: 26 * time.Hour, wantErr: true},
+		{offset: 29*time.Minute + 45500*time.Millisecond, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NewLocalTimeType(tt.offset, true, 4)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewLocalTimeType(%v) error = %v, want error %t", tt.offset, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NewLocalTimeType(%v) = %+v, want %+v", tt.offset, got, tt.want)
+		}
+		if got.Utoffset() != tt.offset {
+			t.Errorf("Utoffset() = %v, want %v", got.Utoffset(), tt.offset)
+		}
+	}
+}
+
+func TestDecodeDataWithOptions_LenientFooter(t *testing.T) {
+	var buf bytes.Buffer
+	want := exampleHonolulu()
+	if err := want.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	truncated := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+
+	if _, err := DecodeData(bytes.NewReader(truncated)); err == nil {
+		t.Errorf("DecodeData() = nil error, want error for missing trailing newline")
+	}
+	got, err := DecodeDataWithOptions(bytes.NewReader(truncated), DecodeOptions{LenientFooter: true})
+	if err != nil {
+		t.Fatalf("DecodeDataWithOptions() error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DecodeDataWithOptions() mismatch (-want +got):\n%s", diff)
+	}
+
+	// The footer must still start with a newline, and the buffered path
+	// behaves the same.
+	for _, r := range []func(string) io.Reader{
+		func(s string) io.Reader { return strings.NewReader(s) },
+		func(s string) io.Reader { return bufio.NewReader(strings.NewReader(s)) },
+	} {
+		if f, err := readFooter(r("\nHST10"), true); err != nil || string(f.TZString) != "HST10" {
+			t.Errorf("readFooter(lenient) = %q, %v, want %q, nil", f.TZString, err, "HST10")
+		}
+		if _, err := readFooter(r("\n"), true); err == nil {
+			t.Errorf("readFooter(lenient) = nil error, want error for missing TZ string")
+		}
+		if _, err := readFooter(r("HST10"), true); err == nil {
+			t.Errorf("readFooter(lenient) = nil error, want error for missing leading newline")
+		}
+	}
+}
+
+func TestComputeCounts(t *testing.T) {
+	for _, name := range []string{"Europe/Zurich", "Pacific/Honolulu"} {
+		d := mustDecodeTestData(t, name)
+		if diff := cmp.Diff(d.V1Header, ComputeV1Counts(d.Version, d.V1Data)); diff != "" {
+			t.Errorf("%s: ComputeV1Counts() mismatch (-want +got):\n%s", name, diff)
+		}
+		if diff := cmp.Diff(d.V2Header, ComputeCounts(d.Version, d.V2Data)); diff != "" {
+			t.Errorf("%s: ComputeCounts() mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+}
+
+func TestData_StripV1(t *testing.T) {
+	var fat bytes.Buffer
+	d := exampleHonolulu()
+	if err := d.Encode(&fat); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeData(&fat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stripped := decoded.StripV1()
+	if err := Validate(stripped); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	var slim bytes.Buffer
+	if err := stripped.Encode(&slim); err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecodeData(&slim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEmptyHeader(got.V1Header) || got.V1Header.Version != V2 {
+		t.Errorf("V1Header = %+v, want empty V2 header", got.V1Header)
+	}
+	want := d
+	want.V1Header, want.V1Data = Header{Version: V2}, V1DataBlock{}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("decoded mismatch (-want +got):\n%s", diff)
+	}
+
+	v1 := Data{Version: V1, V1Header: Header{Version: V1, Typecnt: 1, Charcnt: 4}}
+	if diff := cmp.Diff(v1, v1.StripV1()); diff != "" {
+		t.Errorf("StripV1() of V1 data mismatch (-want +got):\n%s", diff)
+	}
+}
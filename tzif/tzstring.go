@@ -0,0 +1,514 @@
+package tzif
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TZString is a parsed TZ string as found in the footer of a version 2+ TZif file.
+//
+// The TZ string uses the expanded format of the "TZ" environment variable
+// as defined in Section 8.3 of the "Base Definitions" volume of [POSIX],
+// possibly utilizing the extensions described in Section 3.3.1 of RFC 8536:
+//
+//	std offset [dst [offset] [,start[/time],end[/time]]]
+//
+// For example, "CET-1CEST,M3.5.0,M10.5.0/3" describes Central European Time.
+//
+// Note that POSIX offsets are positive west of UT, whereas the offsets in
+// TZString are positive east of UT like the Utoff of a LocalTimeTypeRecord.
+//
+// [POSIX]: https://pubs.opengroup.org/onlinepubs/9699919799/basedefs/V1_chap08.html
+type TZString struct {
+	// StdName is the designation of standard time, for example "CET".
+	StdName string
+	// StdOffset is the offset to add to UT to get standard time.
+	StdOffset time.Duration
+
+	// DstName is the designation of daylight saving time, for example "CEST".
+	// It is empty if the TZ string does not describe daylight saving time,
+	// in which case all other Dst* fields as well as Start and End are zero.
+	DstName string
+	// DstOffset is the offset to add to UT to get daylight saving time.
+	// It defaults to one hour more than StdOffset.
+	DstOffset time.Duration
+
+	// Start is the rule for the transition from standard time to daylight saving time.
+	// Its time is given in local standard time.
+	Start TZTransition
+	// End is the rule for the transition from daylight saving time to standard time.
+	// Its time is given in local daylight saving time.
+	End TZTransition
+}
+
+// HasDST returns true if the TZ string describes daylight saving time.
+func (z TZString) HasDST() bool {
+	return z.DstName != ""
+}
+
+// TZTransitionForm is the form of the date of a TZTransition.
+type TZTransitionForm int
+
+func (f TZTransitionForm) String() string {
+	switch f {
+	case TZJulianDay:
+		return "JulianDay"
+	case TZZeroBasedDay:
+		return "ZeroBasedDay"
+	case TZMonthWeekDay:
+		return "MonthWeekDay"
+	default:
+		return "<UNDEFINED>"
+	}
+}
+
+const (
+	// TZJulianDay is the form "Jn": the Julian day n (1 <= n <= 365).
+	// Leap days are not counted, so February 29 can never be referred to.
+	TZJulianDay TZTransitionForm = iota
+	// TZZeroBasedDay is the form "n": the zero-based Julian day n (0 <= n <= 365).
+	// Leap days are counted, so February 29 can be referred to.
+	TZZeroBasedDay
+	// TZMonthWeekDay is the form "Mm.w.d": day d (0 <= d <= 6) of week w
+	// (1 <= w <= 5) of month m (1 <= m <= 12). Week 1 is the first week in
+	// which day d occurs and week 5 means the last day d in the month.
+	TZMonthWeekDay
+)
+
+// defaultTZTransitionTime is the time of a transition if it is not specified.
+const defaultTZTransitionTime = 2 * time.Hour
+
+// TZTransition is the rule for a transition in a TZString.
+type TZTransition struct {
+	// Form is the form of the date of the transition.
+	Form TZTransitionForm
+	// Day is the day n of the TZJulianDay and TZZeroBasedDay forms.
+	Day int
+	// Month is the month m of the TZMonthWeekDay form.
+	Month time.Month
+	// Week is the week w of the TZMonthWeekDay form.
+	Week int
+	// Weekday is the day d of the TZMonthWeekDay form.
+	Weekday time.Weekday
+	// Time is the local time of the transition relative to 00:00 of the day.
+	// It defaults to 02:00. Version 3 files may use values from -167 to 167 hours.
+	Time time.Duration
+}
+
+// ParseTZString parses a TZ string as found in the footer of a TZif file.
+// The empty string is not a valid TZ string.
+//
+// If the TZ string describes daylight saving time but contains no rules,
+// the rules default to "M3.2.0,M11.1.0" as most implementations do.
+func ParseTZString(s string) (TZString, error) {
+	var (
+		z   TZString
+		err error
+		p   = tzStringParser{s: s}
+	)
+	if z.StdName, err = p.name(); err != nil {
+		return TZString{}, fmt.Errorf("std name: %w", err)
+	}
+	if z.StdOffset, err = p.offset(); err != nil {
+		return TZString{}, fmt.Errorf("std offset: %w", err)
+	}
+	if p.done() {
+		return z, nil
+	}
+
+	if z.DstName, err = p.name(); err != nil {
+		return TZString{}, fmt.Errorf("dst name: %w", err)
+	}
+	z.DstOffset = z.StdOffset + time.Hour
+	if !p.done() && p.peek() != ',' {
+		if z.DstOffset, err = p.offset(); err != nil {
+			return TZString{}, fmt.Errorf("dst offset: %w", err)
+		}
+	}
+	if p.done() {
+		z.Start = TZTransition{Form: TZMonthWeekDay, Month: time.March, Week: 2, Weekday: time.Sunday, Time: defaultTZTransitionTime}
+		z.End = TZTransition{Form: TZMonthWeekDay, Month: time.November, Week: 1, Weekday: time.Sunday, Time: defaultTZTransitionTime}
+		return z, nil
+	}
+
+	if !p.consume(',') {
+		return TZString{}, fmt.Errorf("expected ',' at position %d", p.pos)
+	}
+	if z.Start, err = p.transition(); err != nil {
+		return TZString{}, fmt.Errorf("start rule: %w", err)
+	}
+	if !p.consume(',') {
+		return TZString{}, fmt.Errorf("expected ',' at position %d", p.pos)
+	}
+	if z.End, err = p.transition(); err != nil {
+		return TZString{}, fmt.Errorf("end rule: %w", err)
+	}
+	if !p.done() {
+		return TZString{}, fmt.Errorf("unexpected trailing characters %q", p.s[p.pos:])
+	}
+	return z, nil
+}
+
+// tzStringParser is a cursor over a TZ string.
+type tzStringParser struct {
+	s   string
+	pos int
+}
+
+func (p *tzStringParser) done() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *tzStringParser) peek() byte {
+	return p.s[p.pos]
+}
+
+// consume advances the cursor if the next character is c.
+func (p *tzStringParser) consume(c byte) bool {
+	if !p.done() && p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// name parses a time zone designation. It is either a sequence of at least
+// three alphabetic characters, or a sequence of at least three alphanumeric
+// characters, '+' and '-' enclosed in angle brackets.
+func (p *tzStringParser) name() (string, error) {
+	var name string
+	if p.consume('<') {
+		end := strings.IndexByte(p.s[p.pos:], '>')
+		if end == -1 {
+			return "", errors.New("missing closing '>'")
+		}
+		name = p.s[p.pos : p.pos+end]
+		p.pos += end + 1
+		for i := 0; i < len(name); i++ {
+			if !isAlphanumeric(name[i]) && name[i] != '+' && name[i] != '-' {
+				return "", fmt.Errorf("invalid character %q in quoted name %q", name[i], name)
+			}
+		}
+	} else {
+		start := p.pos
+		for !p.done() && isAlphabetic(p.peek()) {
+			p.pos++
+		}
+		name = p.s[start:p.pos]
+	}
+	if len(name) < 3 {
+		return "", fmt.Errorf("name %q shorter than three characters", name)
+	}
+	return name, nil
+}
+
+// offset parses a POSIX offset of the form [+|-]hh[:mm[:ss]] and returns
+// it with the sign inverted, so that it is positive east of UT.
+func (p *tzStringParser) offset() (time.Duration, error) {
+	d, err := p.hms(24)
+	if err != nil {
+		return 0, err
+	}
+	return -d, nil
+}
+
+// transition parses a rule of the form date[/time].
+func (p *tzStringParser) transition() (TZTransition, error) {
+	var (
+		t   TZTransition
+		err error
+	)
+	switch {
+	case p.consume('M'):
+		var m, w, d int
+		if m, err = p.number(1, 12); err != nil {
+			return t, fmt.Errorf("month: %w", err)
+		}
+		if !p.consume('.') {
+			return t, fmt.Errorf("expected '.' at position %d", p.pos)
+		}
+		if w, err = p.number(1, 5); err != nil {
+			return t, fmt.Errorf("week: %w", err)
+		}
+		if !p.consume('.') {
+			return t, fmt.Errorf("expected '.' at position %d", p.pos)
+		}
+		if d, err = p.number(0, 6); err != nil {
+			return t, fmt.Errorf("weekday: %w", err)
+		}
+		t = TZTransition{Form: TZMonthWeekDay, Month: time.Month(m), Week: w, Weekday: time.Weekday(d)}
+	case p.consume('J'):
+		if t.Day, err = p.number(1, 365); err != nil {
+			return t, fmt.Errorf("julian day: %w", err)
+		}
+		t.Form = TZJulianDay
+	default:
+		if t.Day, err = p.number(0, 365); err != nil {
+			return t, fmt.Errorf("zero-based julian day: %w", err)
+		}
+		t.Form = TZZeroBasedDay
+	}
+
+	t.Time = defaultTZTransitionTime
+	if p.consume('/') {
+		if t.Time, err = p.hms(167); err != nil {
+			return t, fmt.Errorf("time: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// hms parses a duration of the form [+|-]hh[:mm[:ss]] with at most maxHours hours.
+func (p *tzStringParser) hms(maxHours int) (time.Duration, error) {
+	sign := time.Duration(1)
+	if p.consume('-') {
+		sign = -1
+	} else {
+		p.consume('+')
+	}
+	h, err := p.number(0, maxHours)
+	if err != nil {
+		return 0, fmt.Errorf("hours: %w", err)
+	}
+	d := time.Duration(h) * time.Hour
+	if p.consume(':') {
+		m, err := p.number(0, 59)
+		if err != nil {
+			return 0, fmt.Errorf("minutes: %w", err)
+		}
+		d += time.Duration(m) * time.Minute
+		if p.consume(':') {
+			s, err := p.number(0, 59)
+			if err != nil {
+				return 0, fmt.Errorf("seconds: %w", err)
+			}
+			d += time.Duration(s) * time.Second
+		}
+	}
+	return sign * d, nil
+}
+
+// number parses an unsigned decimal number in the range [min, max].
+func (p *tzStringParser) number(min, max int) (int, error) {
+	start := p.pos
+	for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, fmt.Errorf("expected number at position %d", start)
+	}
+	n, err := strconv.Atoi(p.s[start:p.pos])
+	if err != nil {
+		return 0, err
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("%d out of range [%d, %d]", n, min, max)
+	}
+	return n, nil
+}
+
+func isAlphabetic(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isAlphanumeric(c byte) bool {
+	return isAlphabetic(c) || (c >= '0' && c <= '9')
+}
+
+// ExpandTZString returns the transitions described by the TZ string during
+// the given year in chronological order. The year is the calendar year of
+// the local date of the transitions.
+// It returns nil if the TZ string does not describe daylight saving time.
+func ExpandTZString(z TZString, year int) []ResolvedTransition {
+	if !z.HasDST() {
+		return nil
+	}
+	start := ResolvedTransition{
+		Time:         z.Start.instant(year, z.StdOffset),
+		Offset:       z.DstOffset,
+		Dst:          true,
+		Abbreviation: z.DstName,
+	}
+	end := ResolvedTransition{
+		Time:         z.End.instant(year, z.DstOffset),
+		Offset:       z.StdOffset,
+		Dst:          false,
+		Abbreviation: z.StdName,
+	}
+	if end.Time.Before(start.Time) {
+		return []ResolvedTransition{end, start}
+	}
+	return []ResolvedTransition{start, end}
+}
+
+// DataFromTZString returns data for the zone described by a TZ string
+// alone, e.g. from the TZ environment variable. The transitions described
+// by the TZ string during the years fromYear through toYear are stored
+// explicitly, and the TZ string becomes the footer, which describes local
+// time after the last of them.
+//
+// Rule transitions that coincide, such as the end of daylight saving time
+// in one year and its start in the next in TZ strings for year-round DST,
+// and transitions that do not change the local time type are omitted.
+// Local time type 0 is the one that the TZ string gives just before the
+// first stored transition, which is daylight saving time in the southern
+// hemisphere. The version 1 data block holds the transitions that fit into
+// 32 bits. The version is V2, or V3 if the TZ string uses features that
+// require it.
+func DataFromTZString(tz string, fromYear, toYear int) (Data, error) {
+	z, err := ParseTZString(tz)
+	if err != nil {
+		return Data{}, fmt.Errorf("parse TZ string %q: %w", tz, err)
+	}
+	if fromYear > toYear {
+		return Data{}, fmt.Errorf("invalid year range [%d, %d]", fromYear, toYear)
+	}
+	version := V2
+	if _, long := longAbbreviation(z); long || usesTZStringExtensions(z) {
+		version = V3
+	}
+
+	// Expand the years around the range as well, to find the local time
+	// type in effect at its start and transitions at its end that coincide
+	// with the next one. A transition replaces those that it does not
+	// follow, and is omitted if it does not change the DST flag, which
+	// determines the local time type.
+	type yearTransition struct {
+		ResolvedTransition
+		year int
+	}
+	var all []yearTransition
+	for year := fromYear - 1; year <= toYear+1; year++ {
+		for _, t := range ExpandTZString(z, year) {
+			for len(all) > 0 && !all[len(all)-1].Time.Before(t.Time) {
+				all = all[:len(all)-1]
+			}
+			if (len(all) == 0 && !t.Dst) || (len(all) > 0 && all[len(all)-1].Dst == t.Dst) {
+				continue
+			}
+			all = append(all, yearTransition{t, year})
+		}
+	}
+	var (
+		initialDst bool
+		trs        []ResolvedTransition
+	)
+	for _, t := range all {
+		switch {
+		case t.year < fromYear:
+			initialDst = t.Dst
+		case t.year <= toYear:
+			trs = append(trs, t.ResolvedTransition)
+		}
+	}
+
+	// Local time types are added in order of first use, starting with
+	// type 0, so that every type is in effect at some time.
+	var (
+		names   []string
+		records []LocalTimeTypeRecord
+		types   = make(map[bool]uint8)
+	)
+	addType := func(dst bool) {
+		if _, ok := types[dst]; ok {
+			return
+		}
+		r := LocalTimeTypeRecord{Utoff: int32(z.StdOffset / time.Second)}
+		name := z.StdName
+		if dst {
+			r = LocalTimeTypeRecord{Utoff: int32(z.DstOffset / time.Second), Dst: true}
+			name = z.DstName
+		}
+		types[dst] = uint8(len(records))
+		records = append(records, r)
+		names = append(names, name)
+	}
+	addType(initialDst)
+	for _, t := range trs {
+		addType(t.Dst)
+	}
+	designations, indices, _, err := BuildDesignations(names)
+	if err != nil {
+		return Data{}, err
+	}
+	for i := range records {
+		records[i].Idx = indices[i]
+	}
+
+	v2 := V2DataBlock{LocalTimeTypeRecord: records, TimeZoneDesignation: designations}
+	v1 := V1DataBlock{LocalTimeTypeRecord: records, TimeZoneDesignation: designations}
+	for _, t := range trs {
+		typ, sec := types[t.Dst], t.Time.Unix()
+		v2.TransitionTimes = append(v2.TransitionTimes, sec)
+		v2.TransitionTypes = append(v2.TransitionTypes, typ)
+		if sec >= math.MinInt32 && sec <= math.MaxInt32 {
+			v1.TransitionTimes = append(v1.TransitionTimes, int32(sec))
+			v1.TransitionTypes = append(v1.TransitionTypes, typ)
+		}
+	}
+
+	return Data{
+		Version:  version,
+		V1Header: ComputeV1Counts(version, v1),
+		V1Data:   v1,
+		V2Header: ComputeCounts(version, v2),
+		V2Data:   v2,
+		V2Footer: Footer{TZString: []byte(tz)},
+	}, nil
+}
+
+// ResolvedTransition is a transition to a local time type.
+type ResolvedTransition struct {
+	// Time is the instant of the transition.
+	Time time.Time
+	// Offset is the offset to add to UT to get local time after the transition.
+	Offset time.Duration
+	// Dst is true if local time after the transition is daylight saving time.
+	Dst bool
+	// Abbreviation is the time zone designation after the transition.
+	Abbreviation string
+}
+
+// instant returns the instant of the transition in the given year
+// if the local time before the transition has the given offset from UT.
+func (t TZTransition) instant(year int, offset time.Duration) time.Time {
+	month, day := t.ResolveDate(year)
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return midnight.Add(t.Time - offset)
+}
+
+// ResolveDate returns the month and day of month on which the transition
+// occurs in the given year, ignoring the time of day.
+//
+// For the Mm.w.d form, week 5 means the last d day of the month, which may
+// be in the fourth week. For the Jn form, February 29 is never counted, so
+// J60 is always March 1. For the zero-based n form, leap days are counted.
+func (t TZTransition) ResolveDate(year int) (time.Month, int) {
+	switch t.Form {
+	case TZJulianDay:
+		// February 29 is never counted, even in leap years,
+		// so the day is the same as in a non-leap year.
+		d := time.Date(2001, time.January, t.Day, 0, 0, 0, 0, time.UTC)
+		return d.Month(), d.Day()
+	case TZZeroBasedDay:
+		d := time.Date(year, time.January, t.Day+1, 0, 0, 0, 0, time.UTC)
+		return d.Month(), d.Day()
+	default:
+		first := time.Date(year, t.Month, 1, 0, 0, 0, 0, time.UTC)
+		day := 1 + (int(t.Weekday)-int(first.Weekday())+7)%7 + (t.Week-1)*7
+		if daysIn := daysInMonth(year, t.Month); day > daysIn {
+			// Week 5 means the last such weekday in the month.
+			day -= 7
+		}
+		return t.Month, day
+	}
+}
+
+// daysInMonth returns the number of days in the given month.
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,277 @@
+package tzif
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTZString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want TZString
+	}{
+		{
+			in:   "HST10",
+			want: TZString{StdName: "HST", StdOffset: -10 * time.Hour},
+		},
+		{
+			in: "CET-1CEST,M3.5.0,M10.5.0/3",
+			want: TZString{
+				StdName:   "CET",
+				StdOffset: time.Hour,
+				DstName:   "CEST",
+				DstOffset: 2 * time.Hour,
+				Start:     TZTransition{Form: TZMonthWeekDay, Month: time.March, Week: 5, Weekday: time.Sunday, Time: 2 * time.Hour},
+				End:       TZTransition{Form: TZMonthWeekDay, Month: time.October, Week: 5, Weekday: time.Sunday, Time: 3 * time.Hour},
+			},
+		},
+		{
+			in: "<-03>3<-02>,M3.5.0/-2,M10.5.0/-1",
+			want: TZString{
+				StdName:   "-03",
+				StdOffset: -3 * time.Hour,
+				DstName:   "-02",
+				DstOffset: -2 * time.Hour,
+				Start:     TZTransition{Form: TZMonthWeekDay, Month: time.March, Week: 5, Weekday: time.Sunday, Time: -2 * time.Hour},
+				End:       TZTransition{Form: TZMonthWeekDay, Month: time.October, Week: 5, Weekday: time.Sunday, Time: -1 * time.Hour},
+			},
+		},
+		{
+			in: "EST5EDT,0/0,J365/25",
+			want: TZString{
+				StdName:   "EST",
+				StdOffset: -5 * time.Hour,
+				DstName:   "EDT",
+				DstOffset: -4 * time.Hour,
+				Start:     TZTransition{Form: TZZeroBasedDay, Day: 0, Time: 0},
+				End:       TZTransition{Form: TZJulianDay, Day: 365, Time: 25 * time.Hour},
+			},
+		},
+		{
+			in: "<+1030>-10:30<+11>-11,M10.1.0,M4.1.0",
+			want: TZString{
+				StdName:   "+1030",
+				StdOffset: 10*time.Hour + 30*time.Minute,
+				DstName:   "+11",
+				DstOffset: 11 * time.Hour,
+				Start:     TZTransition{Form: TZMonthWeekDay, Month: time.October, Week: 1, Weekday: time.Sunday, Time: 2 * time.Hour},
+				End:       TZTransition{Form: TZMonthWeekDay, Month: time.April, Week: 1, Weekday: time.Sunday, Time: 2 * time.Hour},
+			},
+		},
+		{
+			in: "IST-2:00:00IDT-3:00:00,M3.4.4/26:00:00,M10.5.0/2:00:00",
+			want: TZString{
+				StdName:   "IST",
+				StdOffset: 2 * time.Hour,
+				DstName:   "IDT",
+				DstOffset: 3 * time.Hour,
+				Start:     TZTransition{Form: TZMonthWeekDay, Month: time.March, Week: 4, Weekday: time.Thursday, Time: 26 * time.Hour},
+				End:       TZTransition{Form: TZMonthWeekDay, Month: time.October, Week: 5, Weekday: time.Sunday, Time: 2 * time.Hour},
+			},
+		},
+		{
+			in: "<+0530>-5:30<+0630>-6:30:15,J60/1:30:45,300/-0:30",
+			want: TZString{
+				StdName:   "+0530",
+				StdOffset: 5*time.Hour + 30*time.Minute,
+				DstName:   "+0630",
+				DstOffset: 6*time.Hour + 30*time.Minute + 15*time.Second,
+				Start:     TZTransition{Form: TZJulianDay, Day: 60, Time: time.Hour + 30*time.Minute + 45*time.Second},
+				End:       TZTransition{Form: TZZeroBasedDay, Day: 300, Time: -30 * time.Minute},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTZString(tt.in)
+			if err != nil {
+				t.Fatalf("ParseTZString(%q) failed: %v", tt.in, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseTZString(%q) mismatch (-want +got):\n%s", tt.in, diff)
+			}
+		})
+	}
+}
+
+func TestParseTZString_Invalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"ZZ1",
+		"CET",
+		"CET-1CEST,M3.5.0",
+		"CET-1CEST,M13.5.0,M10.5.0",
+		"CET-1CEST,M3.6.0,M10.5.0",
+		"<CET-1",
+		"CET-1CEST,M3.5.0,M10.5.0/3x",
+	} {
+		if _, err := ParseTZString(in); err == nil {
+			t.Errorf("ParseTZString(%q) = nil error, want error", in)
+		}
+	}
+}
+
+func TestExpandTZString(t *testing.T) {
+	z, err := ParseTZString("CET-1CEST,M3.5.0,M10.5.0/3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ResolvedTransition{
+		{Time: time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC), Offset: 2 * time.Hour, Dst: true, Abbreviation: "CEST"},
+		{Time: time.Date(2024, time.October, 27, 1, 0, 0, 0, time.UTC), Offset: time.Hour, Dst: false, Abbreviation: "CET"},
+	}
+	if diff := cmp.Diff(want, ExpandTZString(z, 2024)); diff != "" {
+		t.Errorf("ExpandTZString() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Southern hemisphere: DST ends before it starts within a calendar year.
+	z, err = ParseTZString("<+1030>-10:30<+11>-11,M10.1.0,M4.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ExpandTZString(z, 2024)
+	if len(got) != 2 || got[0].Dst || !got[1].Dst {
+		t.Errorf("ExpandTZString() = %+v, want end before start", got)
+	}
+}
+
+func TestExpandTZString_SubHour(t *testing.T) {
+	z, err := ParseTZString("<+0530>-5:30<+0630>-6:30,J60/2:00:00,J300/2:00:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ResolvedTransition{
+		// 02:00 at +05:30 on March 1 is 20:30 UT on February 28.
+		{Time: time.Date(2023, time.February, 28, 20, 30, 0, 0, time.UTC), Offset: 6*time.Hour + 30*time.Minute, Dst: true, Abbreviation: "+0630"},
+		// 02:00 at +06:30 on October 27 is 19:30 UT on October 26.
+		{Time: time.Date(2023, time.October, 26, 19, 30, 0, 0, time.UTC), Offset: 5*time.Hour + 30*time.Minute, Dst: false, Abbreviation: "+0530"},
+	}
+	if diff := cmp.Diff(want, ExpandTZString(z, 2023)); diff != "" {
+		t.Errorf("ExpandTZString() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTZTransition_ResolveDate(t *testing.T) {
+	tests := []struct {
+		transition TZTransition
+		year       int
+		wantMonth  time.Month
+		wantDay    int
+	}{
+		// M3.5.0: last Sunday of March.
+		{TZTransition{Form: TZMonthWeekDay, Month: time.March, Week: 5, Weekday: time.Sunday}, 2024, time.March, 31},
+		{TZTransition{Form: TZMonthWeekDay, Month: time.March, Week: 5, Weekday: time.Sunday}, 2025, time.March, 30},
+		// M3.2.0: second Sunday of March.
+		{TZTransition{Form: TZMonthWeekDay, Month: time.March, Week: 2, Weekday: time.Sunday}, 2024, time.March, 10},
+		// J60: March 1, even in leap years.
+		{TZTransition{Form: TZJulianDay, Day: 60}, 2023, time.March, 1},
+		{TZTransition{Form: TZJulianDay, Day: 60}, 2024, time.March, 1},
+		// 59: March 1 in common years, February 29 in leap years.
+		{TZTransition{Form: TZZeroBasedDay, Day: 59}, 2023, time.March, 1},
+		{TZTransition{Form: TZZeroBasedDay, Day: 59}, 2024, time.February, 29},
+	}
+	for _, tt := range tests {
+		month, day := tt.transition.ResolveDate(tt.year)
+		if month != tt.wantMonth || day != tt.wantDay {
+			t.Errorf("%v.ResolveDate(%d) = %v %d, want %v %d", tt.transition.Form, tt.year, month, day, tt.wantMonth, tt.wantDay)
+		}
+	}
+}
+
+func TestDataFromTZString(t *testing.T) {
+	d, err := DataFromTZString("EST5EDT,M3.2.0,M11.1.0", 2020, 2030)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(d); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if d.Version != V2 {
+		t.Errorf("Version = %v, want %v", d.Version, V2)
+	}
+	if n := len(d.V2Data.TransitionTimes); n != 22 {
+		t.Errorf("got %d transitions, want 22", n)
+	}
+
+	got := d.TransitionsOnLocalDate(2025, time.March, 9)
+	want := []ResolvedTransition{
+		{Time: time.Date(2025, time.March, 9, 7, 0, 0, 0, time.UTC), Offset: -4 * time.Hour, Dst: true, Abbreviation: "EDT"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("TransitionsOnLocalDate(2025-03-09) mismatch (-want +got):\n%s", diff)
+	}
+
+	// The footer takes over after the last explicit transition.
+	if got := d.TransitionsOnLocalDate(2031, time.November, 2); len(got) != 1 || got[0].Abbreviation != "EST" {
+		t.Errorf("TransitionsOnLocalDate(2031-11-02) = %v, want transition to EST", got)
+	}
+}
+
+func TestDataFromTZString_PermanentDST(t *testing.T) {
+	// The end of DST in one year coincides with its start in the next.
+	d, err := DataFromTZString("<+13>-13<+14>,0/0,J365/25", 2020, 2022)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(d); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if n := len(d.V2Data.TransitionTimes); n != 0 {
+		t.Errorf("got %d transitions, want none", n)
+	}
+	if r, name := d.InitialType(); !r.Dst || name != "+14" {
+		t.Errorf("InitialType() = %+v, %q, want DST +14", r, name)
+	}
+}
+
+func TestDataFromTZString_SouthernHemisphere(t *testing.T) {
+	d, err := DataFromTZString("<+1030>-10:30<+11>-11,M10.1.0,M4.1.0", 2020, 2021)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(d); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	// January 2020 is in DST, which began in October 2019.
+	if r, name := d.InitialType(); !r.Dst || name != "+11" {
+		t.Errorf("InitialType() = %+v, %q, want DST +11", r, name)
+	}
+	got := d.Transitions(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC))
+	want := []ResolvedTransition{
+		{Time: time.Date(2020, time.April, 4, 15, 0, 0, 0, time.UTC), Offset: 10*time.Hour + 30*time.Minute, Abbreviation: "+1030"},
+		{Time: time.Date(2020, time.October, 3, 15, 30, 0, 0, time.UTC), Offset: 11 * time.Hour, Dst: true, Abbreviation: "+11"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Transitions(2020) mismatch (-want +got):\n%s", diff)
+	}
+	if n := len(d.V2Data.TransitionTimes); n != 4 {
+		t.Errorf("got %d transitions, want 4", n)
+	}
+}
+
+func TestDataFromTZString_NoDST(t *testing.T) {
+	d, err := DataFromTZString("<+0530>-5:30", 2020, 2030)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Validate(d); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if len(d.V2Data.TransitionTimes) != 0 {
+		t.Errorf("got %d transitions, want none", len(d.V2Data.TransitionTimes))
+	}
+	if r, name := d.InitialType(); r.Utoffset() != 5*time.Hour+30*time.Minute || name != "+0530" {
+		t.Errorf("InitialType() = %v, %q, want +05:30 and %q", r, name, "+0530")
+	}
+}
+
+func TestDataFromTZString_Invalid(t *testing.T) {
+	if _, err := DataFromTZString("EST5EDT,M3.2.0", 2020, 2030); err == nil {
+		t.Error("DataFromTZString(invalid TZ string) = nil error, want error")
+	}
+	if _, err := DataFromTZString("EST5EDT,M3.2.0,M11.1.0", 2030, 2020); err == nil {
+		t.Error("DataFromTZString(2030, 2020) = nil error, want error")
+	}
+}
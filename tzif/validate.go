@@ -0,0 +1,445 @@
+package tzif
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// minLeapSecondSpacing is the minimum number of seconds between two
+// leap-second occurrences: 28 days' worth of seconds, minus a potential
+// negative leap second.
+const minLeapSecondSpacing = 2419199
+
+// Validate checks that the given TZif data conforms to the MUST-level
+// requirements of RFC 8536.
+// It returns nil if the data is valid. Otherwise, it returns all
+// violations that were found, joined with errors.Join.
+//
+// For version 2+ files, the version 1 header and data block are validated
+// as well, unless they are empty (all counts zero), as in the example of
+// RFC 8536, Appendix B.3, which has no version 1 data. If both blocks are
+// present, Validate also checks that every version 1 transition selects
+// the same local time type as the version 2+ data at the same instant.
+// The footer is checked against the TZ string rules of the file's version.
+func Validate(d Data) error {
+	var errs error
+	if d.V1Header.Version != d.Version {
+		errs = errors.Join(errs, fmt.Errorf("v1 header version %v does not match file version %v", d.V1Header.Version, d.Version))
+	}
+	if d.Version == V1 || !isEmptyHeader(d.V1Header) {
+		errs = errors.Join(errs, validateV1(d.V1Header, d.V1Data))
+	}
+	if d.Version.IsV2Plus() {
+		if d.V2Header.Version != d.Version {
+			errs = errors.Join(errs, fmt.Errorf("v2 header version %v does not match file version %v", d.V2Header.Version, d.Version))
+		}
+		errs = errors.Join(errs, validateV2(d.V2Header, d.V2Data))
+		errs = errors.Join(errs, validateFooter(d.Version, d.V2Footer, d.V2Data))
+		if !isEmptyHeader(d.V1Header) {
+			errs = errors.Join(errs, validateV1V2Consistency(d.V1Data, d.V2Data))
+		}
+	}
+	return errs
+}
+
+// Level selects the requirements of RFC 8536 checked by ValidateStrict.
+type Level int
+
+const (
+	// MustOnly checks the MUST-level requirements, like Validate.
+	MustOnly Level = iota
+	// IncludeShould additionally checks the SHOULD-level recommendations.
+	IncludeShould
+)
+
+// ValidateStrict checks that the given TZif data conforms to the
+// requirements of RFC 8536 of the given level.
+// Violations of MUST-level requirements are returned as err, like by
+// Validate. With IncludeShould, violations of SHOULD-level
+// recommendations are returned as warnings. These are checked:
+//   - Time values are at least -2**59.
+//   - UT offsets are in the range [-89999, 93599].
+//   - Designations consist of three to six ASCII alphanumerics, '-' and '+'.
+//   - Local time type 0 is standard time.
+//   - Every local time type is type 0 or referred to by a transition.
+//
+// Local time type 0 is in effect before the first transition, so data that
+// begins in daylight saving time usually results from a compilation bug.
+// Unused local time types only bloat the data; see UnreachableTypes.
+//
+// A TZ string beginning with a colon, which it should not, is not a POSIX
+// TZ string and is therefore already reported as an error.
+func ValidateStrict(d Data, level Level) (warnings []error, err error) {
+	err = Validate(d)
+	if level < IncludeShould {
+		return nil, err
+	}
+	if d.Version == V1 || !isEmptyHeader(d.V1Header) {
+		warnings = append(warnings, recommendBlock("v1", v1Block(d.V1Data))...)
+	}
+	if d.Version.IsV2Plus() {
+		warnings = append(warnings, recommendBlock("v2", v2Block(d.V2Data))...)
+	}
+	return warnings, err
+}
+
+// recommendBlock checks the SHOULD-level recommendations for a data block.
+// The prefix identifies the block in warnings.
+func recommendBlock(prefix string, b block) []error {
+	var warnings []error
+	add := func(format string, args ...any) {
+		warnings = append(warnings, fmt.Errorf(prefix+" "+format, args...))
+	}
+	for i, t := range b.transitionTimes {
+		if t < bigBang {
+			add("transition time %d at index %d should be at least -2**59", t, i)
+		}
+	}
+	for i, r := range b.leapSecondRecords {
+		if r.Occur < bigBang {
+			add("leap second record %d: occurrence %d should be at least -2**59", i, r.Occur)
+		}
+	}
+	if len(b.localTimeTypeRecords) > 0 && b.localTimeTypeRecords[0].Dst {
+		add("local time type record 0: initial local time type should not be DST")
+	}
+	if unreachable := unreachableTypes(b.localTimeTypeRecords, b.transitionTypes); len(unreachable) > 0 {
+		add("typecnt %d exceeds the %d local time types in effect; unused types %v", len(b.localTimeTypeRecords), len(b.localTimeTypeRecords)-len(unreachable), unreachable)
+	}
+	for i, r := range b.localTimeTypeRecords {
+		if r.Utoff < minUtoff || r.Utoff > maxUtoff {
+			add("local time type record %d: utoff %d should be in the range [%d, %d]", i, r.Utoff, minUtoff, maxUtoff)
+		}
+		if name, ok := designationAt(b.timeZoneDesignation, r.Idx); ok && !isRecommendedDesignation(name) {
+			add("local time type record %d: designation %q should consist of 3 to 6 ASCII alphanumerics, '-' and '+'", i, name)
+		}
+	}
+	return warnings
+}
+
+// isRecommendedDesignation returns true if the designation consists of at
+// least three and no more than six ASCII alphanumerics, '-' and '+'.
+func isRecommendedDesignation(name string) bool {
+	if len(name) < 3 || len(name) > 6 {
+		return false
+	}
+	for _, c := range []byte(name) {
+		alnum := 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+		if !alnum && c != '-' && c != '+' {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmptyHeader returns true if all counts in the header are zero.
+func isEmptyHeader(h Header) bool {
+	return h.Isutcnt == 0 && h.Isstdcnt == 0 && h.Leapcnt == 0 &&
+		h.Timecnt == 0 && h.Typecnt == 0 && h.Charcnt == 0
+}
+
+// validateV1 validates a version 1 header and data block.
+func validateV1(h Header, b V1DataBlock) error {
+	return validateBlock("v1", h, v1Block(b))
+}
+
+// validateV2 validates a version 2+ header and data block.
+func validateV2(h Header, b V2DataBlock) error {
+	return validateBlock("v2", h, v2Block(b))
+}
+
+// v1Block returns the version-independent view of a version 1 data block.
+func v1Block(b V1DataBlock) block {
+	times := make([]int64, len(b.TransitionTimes))
+	for i, t := range b.TransitionTimes {
+		times[i] = int64(t)
+	}
+	leaps := make([]V2LeapSecondRecord, len(b.LeapSecondRecords))
+	for i, r := range b.LeapSecondRecords {
+		leaps[i] = V2LeapSecondRecord{Occur: int64(r.Occur), Corr: r.Corr}
+	}
+	return block{
+		transitionTimes:        times,
+		transitionTypes:        b.TransitionTypes,
+		localTimeTypeRecords:   b.LocalTimeTypeRecord,
+		timeZoneDesignation:    b.TimeZoneDesignation,
+		leapSecondRecords:      leaps,
+		standardWallIndicators: b.StandardWallIndicators,
+		utLocalIndicators:      b.UTLocalIndicators,
+	}
+}
+
+// v2Block returns the version-independent view of a version 2+ data block.
+func v2Block(b V2DataBlock) block {
+	return block{
+		transitionTimes:        b.TransitionTimes,
+		transitionTypes:        b.TransitionTypes,
+		localTimeTypeRecords:   b.LocalTimeTypeRecord,
+		timeZoneDesignation:    b.TimeZoneDesignation,
+		leapSecondRecords:      b.LeapSecondRecords,
+		standardWallIndicators: b.StandardWallIndicators,
+		utLocalIndicators:      b.UTLocalIndicators,
+	}
+}
+
+// block is a version-independent view of a data block used for validation.
+// Time values of version 1 blocks are widened to 64 bits.
+type block struct {
+	transitionTimes        []int64
+	transitionTypes        []uint8
+	localTimeTypeRecords   []LocalTimeTypeRecord
+	timeZoneDesignation    []byte
+	leapSecondRecords      []V2LeapSecondRecord
+	standardWallIndicators []bool
+	utLocalIndicators      []bool
+}
+
+// validateBlock validates the header counts and the content of a data block.
+// The prefix identifies the block in error messages.
+func validateBlock(prefix string, h Header, b block) error {
+	var errs error
+	add := func(format string, args ...any) {
+		errs = errors.Join(errs, fmt.Errorf(prefix+" "+format, args...))
+	}
+
+	// Header counts.
+	if h.Timecnt != uint32(len(b.transitionTimes)) {
+		add("timecnt %d does not match number of transition times %d", h.Timecnt, len(b.transitionTimes))
+	}
+	if h.Timecnt != uint32(len(b.transitionTypes)) {
+		add("timecnt %d does not match number of transition types %d", h.Timecnt, len(b.transitionTypes))
+	}
+	if h.Typecnt == 0 {
+		add("typecnt must not be zero")
+	}
+	if h.Typecnt != uint32(len(b.localTimeTypeRecords)) {
+		add("typecnt %d does not match number of local time type records %d", h.Typecnt, len(b.localTimeTypeRecords))
+	}
+	if h.Charcnt == 0 {
+		add("charcnt must not be zero")
+	}
+	if h.Charcnt != uint32(len(b.timeZoneDesignation)) {
+		add("charcnt %d does not match length of time zone designations %d", h.Charcnt, len(b.timeZoneDesignation))
+	}
+	if h.Leapcnt != uint32(len(b.leapSecondRecords)) {
+		add("leapcnt %d does not match number of leap second records %d", h.Leapcnt, len(b.leapSecondRecords))
+	}
+	if h.Isstdcnt != 0 && h.Isstdcnt != h.Typecnt {
+		add("isstdcnt %d must be zero or equal to typecnt %d", h.Isstdcnt, h.Typecnt)
+	}
+	if h.Isstdcnt != uint32(len(b.standardWallIndicators)) {
+		add("isstdcnt %d does not match number of standard/wall indicators %d", h.Isstdcnt, len(b.standardWallIndicators))
+	}
+	if h.Isutcnt != 0 && h.Isutcnt != h.Typecnt {
+		add("isutcnt %d must be zero or equal to typecnt %d", h.Isutcnt, h.Typecnt)
+	}
+	if h.Isutcnt != uint32(len(b.utLocalIndicators)) {
+		add("isutcnt %d does not match number of UT/local indicators %d", h.Isutcnt, len(b.utLocalIndicators))
+	}
+
+	// Transitions.
+	for i := 1; i < len(b.transitionTimes); i++ {
+		if b.transitionTimes[i] <= b.transitionTimes[i-1] {
+			add("transition times not strictly ascending at index %d: %d <= %d", i, b.transitionTimes[i], b.transitionTimes[i-1])
+			break
+		}
+	}
+	for i, typ := range b.transitionTypes {
+		if int(typ) >= len(b.localTimeTypeRecords) {
+			add("transition type %d at index %d out of range [0, %d]", typ, i, len(b.localTimeTypeRecords)-1)
+		}
+	}
+
+	// Local time type records.
+	lastNUL := bytes.LastIndexByte(b.timeZoneDesignation, 0)
+	for i, r := range b.localTimeTypeRecords {
+		if r.Utoff == math.MinInt32 {
+			add("local time type record %d: utoff must not be -2**31", i)
+		}
+		switch {
+		case len(b.timeZoneDesignation) == 0:
+			// Reported once as a charcnt error above.
+		case int(r.Idx) >= len(b.timeZoneDesignation):
+			add("local time type record %d: idx %d out of range [0, %d]", i, r.Idx, len(b.timeZoneDesignation)-1)
+		case int(r.Idx) > lastNUL:
+			add("local time type record %d: idx %d points into padding after the last NUL at %d", i, r.Idx, lastNUL)
+		}
+	}
+
+	// Leap second records.
+	// Version 4 allows the first record to have any correction to represent
+	// truncation, and the last record to repeat the previous correction to
+	// denote the expiration of the leap second table.
+	// Only the first pair of out-of-order occurrences is reported, like
+	// for transition times; the spacing is only checked for ascending pairs.
+	leapsUnordered := false
+	for i, r := range b.leapSecondRecords {
+		if i == 0 {
+			if r.Occur < 0 {
+				add("leap second record 0: occurrence %d must be nonnegative", r.Occur)
+			}
+			if !h.Version.SupportsLeapExpiration() && r.Corr != 1 && r.Corr != -1 {
+				add("leap second record 0: correction %d must be 1 or -1", r.Corr)
+			}
+			continue
+		}
+		prev := b.leapSecondRecords[i-1]
+		if r.Occur <= prev.Occur {
+			if !leapsUnordered {
+				add("leap second occurrences not strictly ascending at index %d: %d <= %d", i, r.Occur, prev.Occur)
+				leapsUnordered = true
+			}
+		} else if r.Occur-prev.Occur < minLeapSecondSpacing {
+			add("leap second record %d: occurrence %d less than %d seconds after previous occurrence %d", i, r.Occur, minLeapSecondSpacing, prev.Occur)
+		}
+		isExpiration := h.Version.SupportsLeapExpiration() && i == len(b.leapSecondRecords)-1 && r.Corr == prev.Corr
+		if diff := r.Corr - prev.Corr; diff != 1 && diff != -1 && !isExpiration {
+			add("leap second record %d: correction %d does not differ by exactly one from previous correction %d", i, r.Corr, prev.Corr)
+		}
+	}
+
+	// Indicators.
+	for i, ut := range b.utLocalIndicators {
+		if ut && i < len(b.standardWallIndicators) && !b.standardWallIndicators[i] {
+			add("local time type %d: standard/wall indicator must be set if UT/local indicator is set", i)
+		}
+	}
+
+	return errs
+}
+
+// validateFooter validates the TZ string of the footer for the given version.
+//
+// The TZ string must be consistent with the last transition. Version 2
+// footers must also be POSIX TZ strings, so transition times must be
+// within 0 through 24 hours and abbreviations no longer than POSIX
+// implementations are guaranteed to support. Version 3 relaxes both.
+func validateFooter(v Version, f Footer, b V2DataBlock) error {
+	if len(f.TZString) == 0 {
+		return nil
+	}
+	z, err := ParseTZString(string(f.TZString))
+	if err != nil {
+		return fmt.Errorf("footer: %w", err)
+	}
+	errs := validateFooterConsistency(z, b)
+	if v >= V3 {
+		return errs
+	}
+	if usesTZStringExtensions(z) {
+		errs = errors.Join(errs, fmt.Errorf("footer: TZ string %q uses extensions not allowed in %v", f.TZString, v))
+	}
+	if name, ok := longAbbreviation(z); ok {
+		errs = errors.Join(errs, fmt.Errorf("footer: abbreviation %q longer than %d characters not allowed in %v", name, maxPOSIXAbbreviationLen, v))
+	}
+	return errs
+}
+
+// validateFooterConsistency checks that evaluating the TZ string at the time
+// of the last transition yields the local time type of that transition.
+func validateFooterConsistency(z TZString, b V2DataBlock) error {
+	n := len(b.TransitionTimes)
+	if n == 0 || n > len(b.TransitionTypes) || int(b.TransitionTypes[n-1]) >= len(b.LocalTimeTypeRecord) {
+		return nil // reported by validateV2
+	}
+	t := time.Unix(b.TransitionTimes[n-1], 0).UTC()
+	r := b.LocalTimeTypeRecord[b.TransitionTypes[n-1]]
+	name, _ := designationAt(b.TimeZoneDesignation, r.Idx)
+	want := tzStringAt(z, t)
+	if r.Utoff != int32(want.Offset/time.Second) || r.Dst != want.Dst || name != want.Abbreviation {
+		return fmt.Errorf("footer: TZ string yields local time type {%d %t %q} at last transition %d, but the transition has {%d %t %q}",
+			int32(want.Offset/time.Second), want.Dst, want.Abbreviation, b.TransitionTimes[n-1], r.Utoff, r.Dst, name)
+	}
+	return nil
+}
+
+// tzStringAt returns the local time type that the TZ string yields at t
+// as the Offset, Dst and Abbreviation of a transition.
+func tzStringAt(z TZString, t time.Time) ResolvedTransition {
+	in := ResolvedTransition{Offset: z.StdOffset, Abbreviation: z.StdName}
+	if !z.HasDST() {
+		return in
+	}
+	for year := t.Year() - 1; year <= t.Year(); year++ {
+		for _, tr := range ExpandTZString(z, year) {
+			if !tr.Time.After(t) {
+				in = tr
+			}
+		}
+	}
+	return in
+}
+
+// validateV1V2Consistency checks that every version 1 transition selects
+// a local time type equivalent to the one in effect at the same instant
+// according to the version 2+ data.
+//
+// The version 1 data is usually derived from the version 2+ data by dropping
+// the transitions that do not fit into 32 bits, so the two may differ in
+// their transitions and type indices, but never in the resulting local time.
+// Transitions must be dropped rather than truncated, so every version 1
+// transition time must also be a version 2+ transition time, except for
+// -2**31, which writers use to mark the start of the 32-bit data.
+func validateV1V2Consistency(v1 V1DataBlock, v2 V2DataBlock) error {
+	var errs error
+	v2Times := make(map[int64]bool, len(v2.TransitionTimes))
+	for _, t := range v2.TransitionTimes {
+		v2Times[t] = true
+	}
+	for i, t := range v1.TransitionTimes {
+		if t != math.MinInt32 && !v2Times[int64(t)] {
+			errs = errors.Join(errs, fmt.Errorf("v1 transition %d at %d is not a v2 transition time; out-of-range times must be dropped, not truncated", i, t))
+		}
+	}
+	for i, t := range v1.TransitionTimes {
+		if i >= len(v1.TransitionTypes) || int(v1.TransitionTypes[i]) >= len(v1.LocalTimeTypeRecord) {
+			continue // reported by validateV1
+		}
+		j := typeAt(v2.TransitionTimes, v2.TransitionTypes, int64(t))
+		if j >= len(v2.LocalTimeTypeRecord) {
+			continue // reported by validateV2
+		}
+		r1 := v1.LocalTimeTypeRecord[v1.TransitionTypes[i]]
+		r2 := v2.LocalTimeTypeRecord[j]
+		d1, _ := designationAt(v1.TimeZoneDesignation, r1.Idx)
+		d2, _ := designationAt(v2.TimeZoneDesignation, r2.Idx)
+		if r1.Utoff != r2.Utoff || r1.Dst != r2.Dst || d1 != d2 {
+			errs = errors.Join(errs, fmt.Errorf(
+				"v1 transition %d at %d: local time type {%d %t %q} does not match v2 local time type {%d %t %q}",
+				i, t, r1.Utoff, r1.Dst, d1, r2.Utoff, r2.Dst, d2))
+		}
+	}
+	return errs
+}
+
+// typeAt returns the index of the local time type in effect at time t
+// given the transition times and types of a data block.
+// Before the first transition, local time type 0 is in effect.
+func typeAt(times []int64, types []uint8, t int64) int {
+	typ := 0
+	for i, tt := range times {
+		if tt > t || i >= len(types) {
+			break
+		}
+		typ = int(types[i])
+	}
+	return typ
+}
+
+// designationAt returns the NUL-terminated time zone designation starting at
+// index idx of the given time zone designations.
+// It returns false if idx is out of range or there is no NUL at or after idx.
+func designationAt(designations []byte, idx uint8) (string, bool) {
+	if int(idx) >= len(designations) {
+		return "", false
+	}
+	for i, c := range designations[idx:] {
+		if c == 0 {
+			return string(designations[idx : int(idx)+i]), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,412 @@
+package tzif
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// exampleHonolulu returns the data of example B.2 from RFC 8536,
+// a version 2 file representing Pacific/Honolulu.
+func exampleHonolulu() Data {
+	designations := []byte("LMT\x00HST\x00HDT\x00HWT\x00HPT\x00")
+	records := func() []LocalTimeTypeRecord {
+		return []LocalTimeTypeRecord{
+			{Utoff: -37886, Dst: false, Idx: 0},
+			{Utoff: -37800, Dst: false, Idx: 4},
+			{Utoff: -34200, Dst: true, Idx: 8},
+			{Utoff: -34200, Dst: true, Idx: 12},
+			{Utoff: -34200, Dst: true, Idx: 16},
+			{Utoff: -36000, Dst: false, Idx: 4},
+		}
+	}
+	header := Header{
+		Version:  V2,
+		Isutcnt:  6,
+		Isstdcnt: 6,
+		Timecnt:  7,
+		Typecnt:  6,
+		Charcnt:  20,
+	}
+	return Data{
+		Version:  V2,
+		V1Header: header,
+		V1Data: V1DataBlock{
+			TransitionTimes:        []int32{-2147483648, -1157283000, -1155436200, -880198200, -769395600, -765376200, -712150200},
+			TransitionTypes:        []uint8{1, 2, 1, 3, 4, 1, 5},
+			LocalTimeTypeRecord:    records(),
+			TimeZoneDesignation:    append([]byte(nil), designations...),
+			UTLocalIndicators:      []bool{true, false, false, false, true, false},
+			StandardWallIndicators: []bool{true, false, false, false, true, false},
+		},
+		V2Header: header,
+		V2Data: V2DataBlock{
+			TransitionTimes:        []int64{-2334101314, -1157283000, -1155436200, -880198200, -769395600, -765376200, -712150200},
+			TransitionTypes:        []uint8{1, 2, 1, 3, 4, 1, 5},
+			LocalTimeTypeRecord:    records(),
+			TimeZoneDesignation:    append([]byte(nil), designations...),
+			UTLocalIndicators:      []bool{false, false, false, false, true, false},
+			StandardWallIndicators: []bool{false, false, false, false, true, false},
+		},
+		V2Footer: Footer{TZString: []byte("HST10")},
+	}
+}
+
+// exampleJerusalem returns the data of example B.3 from RFC 8536,
+// a version 3 file representing Asia/Jerusalem without version 1 data.
+func exampleJerusalem() Data {
+	return Data{
+		Version:  V3,
+		V1Header: Header{Version: V3},
+		V2Header: Header{
+			Version:  V3,
+			Isutcnt:  1,
+			Isstdcnt: 1,
+			Timecnt:  1,
+			Typecnt:  1,
+			Charcnt:  4,
+		},
+		V2Data: V2DataBlock{
+			TransitionTimes: []int64{2145916800},
+			TransitionTypes: []uint8{0},
+			LocalTimeTypeRecord: []LocalTimeTypeRecord{
+				{Utoff: 7200, Dst: false, Idx: 0},
+			},
+			TimeZoneDesignation:    []byte("IST\x00"),
+			UTLocalIndicators:      []bool{true},
+			StandardWallIndicators: []bool{true},
+		},
+		V2Footer: Footer{TZString: []byte("IST-2IDT,M3.4.4/26,M10.5.0")},
+	}
+}
+
+func TestValidate_RFCExamples(t *testing.T) {
+	tests := map[string]Data{
+		"Honolulu":  exampleHonolulu(),
+		"Jerusalem": exampleJerusalem(),
+	}
+	for name, d := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := Validate(d); err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestValidate_HeaderCounts(t *testing.T) {
+	d := exampleHonolulu()
+	d.V2Header.Timecnt = 6
+	err := Validate(d)
+	if err == nil {
+		t.Fatal("Validate() = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "v2 timecnt 6 does not match number of transition times 7") {
+		t.Errorf("Validate() = %v, want timecnt error", err)
+	}
+}
+
+func TestValidate_IndicatorCounts(t *testing.T) {
+	tests := []struct {
+		count   uint32
+		wantErr bool
+	}{
+		{count: 0},
+		{count: 6},                // typecnt
+		{count: 5, wantErr: true}, // typecnt-1
+	}
+	for _, version := range []string{"v1", "v2"} {
+		for _, field := range []string{"isstdcnt", "isutcnt"} {
+			for _, tt := range tests {
+				d := exampleHonolulu()
+				h, indicators := &d.V2Header, &d.V2Data.StandardWallIndicators
+				if version == "v1" {
+					h, indicators = &d.V1Header, &d.V1Data.StandardWallIndicators
+				}
+				cnt := &h.Isstdcnt
+				if field == "isutcnt" {
+					cnt, indicators = &h.Isutcnt, &d.V2Data.UTLocalIndicators
+					if version == "v1" {
+						indicators = &d.V1Data.UTLocalIndicators
+					}
+				}
+				*cnt = tt.count
+				*indicators = (*indicators)[:tt.count]
+
+				err := Validate(d)
+				want := fmt.Sprintf("%s %s %d must be zero or equal to typecnt 6", version, field, tt.count)
+				if tt.wantErr {
+					if err == nil || !strings.Contains(err.Error(), want) {
+						t.Errorf("%s %s=%d: Validate() = %v, want error %q", version, field, tt.count, err, want)
+					}
+				} else if err != nil {
+					t.Errorf("%s %s=%d: Validate() = %v, want nil", version, field, tt.count, err)
+				}
+			}
+		}
+	}
+}
+
+func TestValidate_V1V2Mismatch(t *testing.T) {
+	d := exampleHonolulu()
+	// Make the V1 record used by the last transition disagree with its V2 counterpart.
+	d.V1Data.LocalTimeTypeRecord[5].Utoff = -36060
+	err := Validate(d)
+	if err == nil {
+		t.Fatal("Validate() = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "v1 transition 6 at -712150200") {
+		t.Errorf("Validate() = %v, want mismatch at v1 transition 6", err)
+	}
+
+	d = exampleHonolulu()
+	// Point a V1 transition to a different record than V2 does.
+	d.V1Data.TransitionTypes[1] = 3
+	err = Validate(d)
+	if err == nil {
+		t.Fatal("Validate() = nil, want error")
+	}
+	if !strings.Contains(err.Error(), `{-34200 true "HWT"} does not match v2 local time type {-34200 true "HDT"}`) {
+		t.Errorf("Validate() = %v, want designation mismatch", err)
+	}
+}
+
+func TestValidate_FooterAbbreviationLength(t *testing.T) {
+	d := exampleHonolulu()
+	d.V2Footer.TZString = []byte("<HAWAIISTDT>10")
+
+	err := Validate(d)
+	if err == nil {
+		t.Fatal("Validate() = nil, want error for V2")
+	}
+	if !strings.Contains(err.Error(), `abbreviation "HAWAIISTDT" longer than 6 characters not allowed in V2 (0x32)`) {
+		t.Errorf("Validate() = %v, want abbreviation length error", err)
+	}
+
+	d.Version, d.V1Header.Version, d.V2Header.Version = V3, V3, V3
+	if err := Validate(d); err != nil && strings.Contains(err.Error(), "longer than") {
+		t.Errorf("Validate() = %v, want no abbreviation length error for V3", err)
+	}
+}
+
+func TestValidate_LeapSecondOccurrences(t *testing.T) {
+	withLeaps := func(leaps ...V2LeapSecondRecord) Data {
+		d := exampleHonolulu()
+		d.V2Header.Leapcnt = uint32(len(leaps))
+		d.V2Data.LeapSecondRecords = leaps
+		return d
+	}
+
+	// Ascending, but the second leap second follows the first within a day.
+	err := Validate(withLeaps(
+		V2LeapSecondRecord{Occur: 78796800, Corr: 1},
+		V2LeapSecondRecord{Occur: 78796800 + 86400, Corr: 2},
+	))
+	if err == nil || !strings.Contains(err.Error(), "less than 2419199 seconds after previous occurrence") {
+		t.Errorf("Validate() = %v, want spacing error", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "not strictly ascending") {
+		t.Errorf("Validate() = %v, want no ordering error", err)
+	}
+
+	// Out of order.
+	err = Validate(withLeaps(
+		V2LeapSecondRecord{Occur: 94694401, Corr: 1},
+		V2LeapSecondRecord{Occur: 78796800, Corr: 2},
+	))
+	if err == nil || !strings.Contains(err.Error(), "v2 leap second occurrences not strictly ascending at index 1: 78796800 <= 94694401") {
+		t.Errorf("Validate() = %v, want ordering error", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "seconds after previous occurrence") {
+		t.Errorf("Validate() = %v, want no spacing error", err)
+	}
+}
+
+func TestValidate_DesignationPadding(t *testing.T) {
+	d := exampleHonolulu()
+	// Append padding after the NUL of the last designation and point a
+	// record into it.
+	d.V2Data.TimeZoneDesignation = append(d.V2Data.TimeZoneDesignation, 'X', 'X')
+	d.V2Header.Charcnt += 2
+	d.V2Data.LocalTimeTypeRecord[5].Idx = 20
+	err := Validate(d)
+	if err == nil || !strings.Contains(err.Error(), "v2 local time type record 5: idx 20 points into padding after the last NUL at 19") {
+		t.Errorf("Validate() = %v, want padding error", err)
+	}
+
+	// Padding that is not referenced is fine.
+	d.V2Data.LocalTimeTypeRecord[5].Idx = 4
+	if err := Validate(d); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestValidate_FooterConsistency(t *testing.T) {
+	d := exampleHonolulu()
+	// The last transition is to HST (UT-10), but the TZ string says UT-11.
+	d.V2Footer.TZString = []byte("HST11")
+	err := Validate(d)
+	if err == nil || !strings.Contains(err.Error(), `footer: TZ string yields local time type {-39600 false "HST"} at last transition -712150200, but the transition has {-36000 false "HST"}`) {
+		t.Errorf("Validate() = %v, want footer consistency error", err)
+	}
+
+	// At the last transition of example B.3, standard time is in effect.
+	d = exampleJerusalem()
+	d.V2Footer.TZString = []byte("IDT-3")
+	if err := Validate(d); err == nil || !strings.Contains(err.Error(), "footer: TZ string yields") {
+		t.Errorf("Validate() = %v, want footer consistency error", err)
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	// Valid at the MUST level, but violating SHOULD-level recommendations.
+	d := exampleJerusalem()
+	d.V2Data.TransitionTimes[0] = -1 << 60
+	d.V2Data.LocalTimeTypeRecord[0].Utoff = 93600
+	d.V2Data.TimeZoneDesignation = []byte("I_T\x00")
+	d.V2Footer.TZString = nil
+
+	for _, level := range []Level{MustOnly, IncludeShould} {
+		warnings, err := ValidateStrict(d, level)
+		if err != nil {
+			t.Errorf("ValidateStrict(%d) error = %v, want nil", level, err)
+		}
+		if level == MustOnly {
+			if warnings != nil {
+				t.Errorf("ValidateStrict(MustOnly) warnings = %v, want nil", warnings)
+			}
+			continue
+		}
+		var got []string
+		for _, w := range warnings {
+			got = append(got, w.Error())
+		}
+		want := []string{
+			"v2 transition time -1152921504606846976 at index 0 should be at least -2**59",
+			"v2 local time type record 0: utoff 93600 should be in the range [-89999, 93599]",
+			`v2 local time type record 0: designation "I_T" should consist of 3 to 6 ASCII alphanumerics, '-' and '+'`,
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ValidateStrict(IncludeShould) warnings mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	// The examples of the RFC follow all recommendations.
+	for _, d := range []Data{exampleHonolulu(), exampleJerusalem()} {
+		if warnings, err := ValidateStrict(d, IncludeShould); warnings != nil || err != nil {
+			t.Errorf("ValidateStrict() = %v, %v, want nil, nil", warnings, err)
+		}
+	}
+}
+
+func TestValidateStrict_InitialDST(t *testing.T) {
+	d := exampleHonolulu()
+	// Swap LMT and HDT, so that the data begins in DST.
+	for _, records := range [][]LocalTimeTypeRecord{d.V1Data.LocalTimeTypeRecord, d.V2Data.LocalTimeTypeRecord} {
+		records[0], records[2] = records[2], records[0]
+	}
+	for _, types := range [][]uint8{d.V1Data.TransitionTypes, d.V2Data.TransitionTypes} {
+		for i, typ := range types {
+			if typ == 2 {
+				types[i] = 0
+			}
+		}
+	}
+	warnings, err := ValidateStrict(d, IncludeShould)
+	if err != nil {
+		t.Fatalf("ValidateStrict() error = %v, want nil", err)
+	}
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.Error())
+	}
+	want := []string{
+		"v1 local time type record 0: initial local time type should not be DST",
+		"v1 typecnt 6 exceeds the 5 local time types in effect; unused types [2]", // LMT
+		"v2 local time type record 0: initial local time type should not be DST",
+		"v2 typecnt 6 exceeds the 5 local time types in effect; unused types [2]",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ValidateStrict() warnings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidate_ZeroCharcnt(t *testing.T) {
+	d := Data{
+		Version:  V1,
+		V1Header: Header{Version: V1, Typecnt: 1},
+		V1Data:   V1DataBlock{LocalTimeTypeRecord: []LocalTimeTypeRecord{{Utoff: 3600}}},
+	}
+	err := Validate(d)
+	if err == nil || err.Error() != "v1 charcnt must not be zero" {
+		t.Errorf("Validate() = %v, want only the charcnt error", err)
+	}
+
+	var buf bytes.Buffer
+	if err := d.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeData(bytes.NewReader(buf.Bytes())); err == nil || !strings.Contains(err.Error(), "charcnt must not be zero") {
+		t.Errorf("DecodeData() = %v, want charcnt error", err)
+	}
+	var dec Decoder
+	if _, err := dec.Decode(bytes.NewReader(buf.Bytes())); err == nil || !strings.Contains(err.Error(), "charcnt must not be zero") {
+		t.Errorf("Decoder.Decode() = %v, want charcnt error", err)
+	}
+}
+
+func TestValidate_V1TruncatedTransition(t *testing.T) {
+	// A transition beyond 2038 is only present in the V2 data.
+	d := exampleHonolulu()
+	beyond2038 := int64(1)<<32 + 1
+	d.V2Data.TransitionTimes = append(d.V2Data.TransitionTimes, beyond2038)
+	d.V2Data.TransitionTypes = append(d.V2Data.TransitionTypes, 1)
+	d.V2Header.Timecnt++
+	d.V2Footer.TZString = nil
+	if err := Validate(d); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	// Truncating it to 32 bits in the V1 data is an error.
+	d.V1Data.TransitionTimes = append(d.V1Data.TransitionTimes, int32(beyond2038))
+	d.V1Data.TransitionTypes = append(d.V1Data.TransitionTypes, 1)
+	d.V1Header.Timecnt++
+	err := Validate(d)
+	if err == nil || !strings.Contains(err.Error(), "v1 transition 7 at 1 is not a v2 transition time") {
+		t.Errorf("Validate() = %v, want truncation error", err)
+	}
+
+	// The fat fixtures compiled by zic are consistent.
+	for _, name := range []string{"Europe/Zurich", "Pacific/Honolulu"} {
+		if err := Validate(mustDecodeTestData(t, name)); err != nil {
+			t.Errorf("%s: Validate() = %v, want nil", name, err)
+		}
+	}
+}
+
+func TestValidateStrict_UnusedTypes(t *testing.T) {
+	d := exampleJerusalem()
+	d.V2Data.LocalTimeTypeRecord = append(d.V2Data.LocalTimeTypeRecord,
+		LocalTimeTypeRecord{Utoff: 10800, Dst: true, Idx: 0},
+		LocalTimeTypeRecord{Utoff: 7200, Idx: 0},
+	)
+	d.V2Data.UTLocalIndicators = nil
+	d.V2Data.StandardWallIndicators = nil
+	d.V2Header = ComputeCounts(V3, d.V2Data)
+
+	warnings, err := ValidateStrict(d, IncludeShould)
+	if err != nil {
+		t.Fatalf("ValidateStrict() error = %v, want nil", err)
+	}
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.Error())
+	}
+	want := []string{"v2 typecnt 3 exceeds the 1 local time types in effect; unused types [1 2]"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ValidateStrict() warnings mismatch (-want +got):\n%s", diff)
+	}
+}
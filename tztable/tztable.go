@@ -0,0 +1,138 @@
+// Package tztable renders the transitions of TZif data as tables for
+// documentation, in Markdown or HTML.
+//
+// Each row of a table describes one transition: the instant in UTC, and
+// the UT offset, time zone designation and DST flag of the local time type
+// in effect after it.
+package tztable
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-tz/tz/tzif"
+)
+
+// Format is the output format of a table.
+type Format int
+
+const (
+	// Markdown is a GitHub Flavored Markdown table, preceded by a heading
+	// with the name of the zone.
+	Markdown Format = iota
+	// HTML is an HTML table with the name of the zone as its caption.
+	HTML
+)
+
+func (f Format) String() string {
+	switch f {
+	case Markdown:
+		return "Markdown"
+	case HTML:
+		return "HTML"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// dateLayout is the layout of the instants of transitions.
+const dateLayout = "2006-01-02 15:04:05 UTC"
+
+// header is the header row of a table.
+var header = [...]string{"Date", "Offset", "Abbreviation", "DST"}
+
+// Write writes a table of the transitions of d in the half-open interval
+// [from, to) to w, in the given format. The name of the zone is used as
+// the heading or caption of the table. After the last transition of the
+// data, the TZ string in the footer is consulted.
+func Write(w io.Writer, name string, d tzif.Data, from, to time.Time, f Format) error {
+	var rows [][len(header)]string
+	for _, tr := range d.Transitions(from, to) {
+		dst := "no"
+		if tr.Dst {
+			dst = "yes"
+		}
+		rows = append(rows, [len(header)]string{
+			tr.Time.UTC().Format(dateLayout),
+			formatOffset(tr.Offset),
+			tr.Abbreviation,
+			dst,
+		})
+	}
+
+	var sb strings.Builder
+	switch f {
+	case Markdown:
+		writeMarkdown(&sb, name, rows)
+	case HTML:
+		writeHTML(&sb, name, rows)
+	default:
+		return fmt.Errorf("unknown format %v", f)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeMarkdown(sb *strings.Builder, name string, rows [][len(header)]string) {
+	sb.WriteString("## " + escapeMarkdown(name) + "\n\n")
+	writeMarkdownRow(sb, header)
+	sb.WriteString("|---|---|---|---|\n")
+	for _, r := range rows {
+		writeMarkdownRow(sb, r)
+	}
+}
+
+func writeMarkdownRow(sb *strings.Builder, cells [len(header)]string) {
+	sb.WriteString("|")
+	for _, c := range cells {
+		sb.WriteString(" " + escapeMarkdown(c) + " |")
+	}
+	sb.WriteString("\n")
+}
+
+// escapeMarkdown escapes the characters that end a table cell or start
+// inline markup.
+var escapeMarkdown = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"<", "&lt;",
+).Replace
+
+func writeHTML(sb *strings.Builder, name string, rows [][len(header)]string) {
+	sb.WriteString("<table>\n")
+	sb.WriteString("<caption>" + html.EscapeString(name) + "</caption>\n")
+	writeHTMLRow(sb, "th", header)
+	for _, r := range rows {
+		writeHTMLRow(sb, "td", r)
+	}
+	sb.WriteString("</table>\n")
+}
+
+func writeHTMLRow(sb *strings.Builder, tag string, cells [len(header)]string) {
+	sb.WriteString("<tr>")
+	for _, c := range cells {
+		sb.WriteString("<" + tag + ">" + html.EscapeString(c) + "</" + tag + ">")
+	}
+	sb.WriteString("</tr>\n")
+}
+
+// formatOffset formats a UT offset as ("+" / "-") hh:mm [:ss].
+func formatOffset(d time.Duration) string {
+	sign := '+'
+	if d < 0 {
+		sign = '-'
+		d = -d
+	}
+	secs := int(d / time.Second)
+	h, m, s := secs/3600, secs/60%60, secs%60
+	if s != 0 {
+		return fmt.Sprintf("%c%02d:%02d:%02d", sign, h, m, s)
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, h, m)
+}
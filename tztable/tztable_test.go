@@ -0,0 +1,79 @@
+package tztable
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/go-tz/tz/tzif"
+)
+
+// mustDecodeTestData decodes a TZif file from the testdata directory.
+func mustDecodeTestData(t *testing.T, name string) tzif.Data {
+	t.Helper()
+	b, err := os.ReadFile("../testdata/zoneinfo/" + name)
+	if err != nil {
+		t.Fatalf("failed to read testdata: %v", err)
+	}
+	d, err := tzif.DecodeData(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("failed to decode testdata: %v", err)
+	}
+	return d
+}
+
+var (
+	from = time.Date(1850, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to   = time.Date(1942, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestWrite_Markdown(t *testing.T) {
+	d := mustDecodeTestData(t, "Europe/Zurich")
+	var sb strings.Builder
+	if err := Write(&sb, "Europe/Zurich", d, from, to, Markdown); err != nil {
+		t.Fatal(err)
+	}
+	want := `## Europe/Zurich
+
+| Date | Offset | Abbreviation | DST |
+|---|---|---|---|
+| 1853-07-15 23:25:52 UTC | +00:29:46 | BMT | no |
+| 1894-05-31 23:30:14 UTC | +01:00 | CET | no |
+| 1941-05-05 00:00:00 UTC | +02:00 | CEST | yes |
+| 1941-10-06 00:00:00 UTC | +01:00 | CET | no |
+`
+	if diff := cmp.Diff(want, sb.String()); diff != "" {
+		t.Errorf("Write() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWrite_HTML(t *testing.T) {
+	d := mustDecodeTestData(t, "Europe/Zurich")
+	var sb strings.Builder
+	from := time.Date(1941, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if err := Write(&sb, "Europe/Zurich", d, from, to, HTML); err != nil {
+		t.Fatal(err)
+	}
+	want := `<table>
+<caption>Europe/Zurich</caption>
+<tr><th>Date</th><th>Offset</th><th>Abbreviation</th><th>DST</th></tr>
+<tr><td>1941-05-05 00:00:00 UTC</td><td>+02:00</td><td>CEST</td><td>yes</td></tr>
+<tr><td>1941-10-06 00:00:00 UTC</td><td>+01:00</td><td>CET</td><td>no</td></tr>
+</table>
+`
+	if diff := cmp.Diff(want, sb.String()); diff != "" {
+		t.Errorf("Write() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	d := mustDecodeTestData(t, "Europe/Zurich")
+	if err := Write(io.Discard, "Europe/Zurich", d, from, to, Format(-1)); err == nil {
+		t.Error("Write() = nil error, want error for unknown format")
+	}
+}